@@ -0,0 +1,219 @@
+package goapl
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// 队列满时的处理策略
+type OverflowPolicy int
+
+const (
+	OVERFLOW_BLOCK       OverflowPolicy = iota // 阻塞直到有空位
+	OVERFLOW_DROP_OLDEST                       // 丢弃队列中最早的元素
+	OVERFLOW_DROP_NEWEST                       // 丢弃新加入的元素
+)
+
+var (
+	ErrQueueFull   = errors.New("goapl: queue is full")
+	ErrQueueClosed = errors.New("goapl: queue closed")
+)
+
+// 有界阻塞队列的统计
+type QueueStats struct {
+	Len       int    // 当前元素个数
+	Capacity  int    // 容量
+	HighWater int    // 元素个数的历史最大值
+	Put       uint64 // 成功放入的次数
+	Taken     uint64 // 取出的次数
+	Dropped   uint64 // 因队列满被丢弃的次数
+}
+
+// 有界阻塞队列, 用于生产者消费者模型. 队列满时按OverflowPolicy处理,
+// Close后不能再放入, 消费者取完剩余元素后得到ErrQueueClosed
+type BlockingQueue[T any] struct {
+	items    Deque[T]
+	capacity int
+	policy   OverflowPolicy
+	onDrop   func(n T)
+	closed   bool
+	stats    QueueStats
+	lock     sync.Mutex
+	changed  chan struct{}
+}
+
+func NewBlockingQueue[T any](capacity int, policy OverflowPolicy) *BlockingQueue[T] {
+	if capacity <= 0 {
+		capacity = 1
+	}
+
+	return &BlockingQueue[T]{
+		items:    *NewDequeSize[T](capacity),
+		capacity: capacity,
+		policy:   policy,
+		changed:  make(chan struct{}),
+	}
+}
+
+// 设置元素被丢弃时的回调, 回调在持有队列锁时执行, 不能再操作队列
+func (q *BlockingQueue[T]) SetDropHandler(fn func(n T)) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.onDrop = fn
+}
+
+// 放入元素. OVERFLOW_BLOCK时队列满会等待直到有空位, ctx取消或队列关闭;
+// OVERFLOW_DROP_NEWEST时丢弃n并返回ErrQueueFull; OVERFLOW_DROP_OLDEST时丢弃队头
+func (q *BlockingQueue[T]) Put(ctx context.Context, n T) error {
+	for {
+		q.lock.Lock()
+		if q.closed {
+			q.lock.Unlock()
+			return ErrQueueClosed
+		}
+
+		if q.items.Len() < q.capacity || q.policy != OVERFLOW_BLOCK {
+			err := q.put(n)
+			q.lock.Unlock()
+			return err
+		}
+
+		changed := q.changed
+		q.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// 不等待的放入, OVERFLOW_BLOCK时队列满返回ErrQueueFull
+func (q *BlockingQueue[T]) TryPut(n T) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	if q.items.Len() >= q.capacity && q.policy == OVERFLOW_BLOCK {
+		return ErrQueueFull
+	}
+	return q.put(n)
+}
+
+// 取出队头, 队列为空时等待直到有元素, ctx取消或队列关闭
+func (q *BlockingQueue[T]) Take(ctx context.Context) (T, error) {
+	for {
+		q.lock.Lock()
+		if q.items.Len() > 0 || q.closed {
+			n, err := q.take()
+			q.lock.Unlock()
+			return n, err
+		}
+
+		changed := q.changed
+		q.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// 不等待的取出, 队列为空时返回ErrEmpty, 已关闭且为空时返回ErrQueueClosed
+func (q *BlockingQueue[T]) TryTake() (T, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.items.Len() == 0 && !q.closed {
+		var zero T
+		return zero, ErrEmpty
+	}
+	return q.take()
+}
+
+// 关闭队列, 等待中的Put返回ErrQueueClosed, 剩余元素仍可取出
+func (q *BlockingQueue[T]) Close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if !q.closed {
+		q.closed = true
+		q.notify()
+	}
+}
+
+func (q *BlockingQueue[T]) IsClosed() bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.closed
+}
+
+func (q *BlockingQueue[T]) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.items.Len()
+}
+
+func (q *BlockingQueue[T]) Stats() QueueStats {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	stats := q.stats
+	stats.Len = q.items.Len()
+	stats.Capacity = q.capacity
+	return stats
+}
+
+// 需持有锁, 队列未关闭
+func (q *BlockingQueue[T]) put(n T) error {
+	if q.items.Len() >= q.capacity {
+		q.stats.Dropped++
+		if q.policy == OVERFLOW_DROP_NEWEST {
+			q.drop(n)
+			return ErrQueueFull
+		}
+
+		oldest, _ := q.items.PopFront()
+		q.drop(oldest)
+	}
+
+	q.items.PushBack(n)
+	q.stats.Put++
+	if q.items.Len() > q.stats.HighWater {
+		q.stats.HighWater = q.items.Len()
+	}
+	q.notify()
+	return nil
+}
+
+// 需持有锁
+func (q *BlockingQueue[T]) take() (T, error) {
+	n, err := q.items.PopFront()
+	if err != nil {
+		return n, ErrQueueClosed
+	}
+
+	q.stats.Taken++
+	q.notify()
+	return n, nil
+}
+
+func (q *BlockingQueue[T]) drop(n T) {
+	if q.onDrop != nil {
+		q.onDrop(n)
+	}
+}
+
+// 唤醒所有等待者, 需持有锁
+func (q *BlockingQueue[T]) notify() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
@@ -0,0 +1,123 @@
+package goapl
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBlockingQueue(t *testing.T) {
+	q := NewBlockingQueue[int](2, OVERFLOW_BLOCK)
+	ctx := context.Background()
+
+	q.Put(ctx, 1)
+	q.Put(ctx, 2)
+	if err := q.TryPut(3); err != ErrQueueFull {
+		t.Fatal("ERR:full queue should reject TryPut")
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := q.Put(timeout, 3); err != context.DeadlineExceeded {
+		t.Fatal("ERR:blocked Put should honor context,", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- q.Put(ctx, 3)
+	}()
+
+	if n, _ := q.Take(ctx); n != 1 {
+		t.Fatal("ERR:take should return 1")
+	}
+	if err := <-done; err != nil {
+		t.Fatal("ERR:blocked Put should succeed after Take,", err)
+	}
+
+	q.Close()
+	if err := q.Put(ctx, 4); err != ErrQueueClosed {
+		t.Error("ERR:closed queue should reject Put")
+	}
+
+	// 关闭后剩余元素仍可取出
+	a, _ := q.Take(ctx)
+	b, _ := q.TryTake()
+	if a != 2 || b != 3 {
+		t.Errorf("ERR:remaining items should be drained, got %d %d", a, b)
+	}
+
+	if _, err := q.Take(ctx); err != ErrQueueClosed {
+		t.Error("ERR:drained closed queue should return ErrQueueClosed")
+	}
+
+	stats := q.Stats()
+	if stats.Put != 3 || stats.Taken != 3 || stats.HighWater != 2 || stats.Capacity != 2 {
+		t.Errorf("ERR:stats wrong, %+v", stats)
+	}
+}
+
+func TestBlockingQueueDrop(t *testing.T) {
+	var dropped []int
+	q := NewBlockingQueue[int](2, OVERFLOW_DROP_OLDEST)
+	q.SetDropHandler(func(n int) {
+		dropped = append(dropped, n)
+	})
+
+	for i := 1; i <= 4; i++ {
+		if err := q.TryPut(i); err != nil {
+			t.Fatal("ERR:drop oldest should accept,", err)
+		}
+	}
+
+	if n, _ := q.TryTake(); n != 3 || len(dropped) != 2 || dropped[0] != 1 {
+		t.Errorf("ERR:oldest items should be dropped, got %d %v", n, dropped)
+	}
+
+	q = NewBlockingQueue[int](1, OVERFLOW_DROP_NEWEST)
+	q.TryPut(1)
+	if err := q.TryPut(2); err != ErrQueueFull || q.Stats().Dropped != 1 {
+		t.Error("ERR:drop newest should reject new item")
+	}
+
+	if _, err := NewBlockingQueue[int](1, OVERFLOW_BLOCK).TryTake(); err != ErrEmpty {
+		t.Error("ERR:empty queue TryTake should return ErrEmpty")
+	}
+}
+
+func TestBlockingQueueConcurrent(t *testing.T) {
+	q := NewBlockingQueue[int](4, OVERFLOW_BLOCK)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				q.Put(ctx, j)
+			}
+		}()
+	}
+
+	total := 0
+	consumed := make(chan struct{})
+	go func() {
+		defer close(consumed)
+		for {
+			n, err := q.Take(ctx)
+			if err != nil {
+				return
+			}
+			total += n
+		}
+	}()
+
+	wg.Wait()
+	q.Close()
+	<-consumed
+
+	if total != 4*4950 {
+		t.Errorf("ERR:all items should be consumed, got %d", total)
+	}
+}
@@ -0,0 +1,258 @@
+package goapl
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sambios/goapl/estimer"
+)
+
+// 缓存条目被淘汰的原因
+type EvictReason int
+
+const (
+	EVICT_CAPACITY EvictReason = iota // 超出容量, 淘汰最久未使用的
+	EVICT_EXPIRED                     // 超过存活时间
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EVICT_CAPACITY:
+		return "capacity"
+	case EVICT_EXPIRED:
+		return "expired"
+	}
+	return "unknown"
+}
+
+// 缓存配置
+type CacheOptions struct {
+	Capacity int                     // 最大条目数, 0为不限制
+	TTL      time.Duration           // 默认存活时间, 0为永不过期
+	Timers   *estimer.HeapTimerQueue // 驱动过期的定时器队列, 为nil时缓存在第一次需要时自己创建, 并在Close时停止
+
+	// 设置后每次淘汰都会在Dispatcher上派发EvictEvent事件, Data为*CacheEviction
+	Dispatcher *EventDispatcher
+	EvictEvent uint32
+}
+
+// 淘汰事件的数据
+type CacheEviction struct {
+	Key    interface{}
+	Value  interface{}
+	Reason EvictReason
+}
+
+// 缓存统计
+type CacheStats struct {
+	Len         int
+	Hits        uint64
+	Misses      uint64
+	Evictions   uint64 // 因容量被淘汰的次数
+	Expirations uint64 // 因过期被淘汰的次数
+}
+
+type cacheEntry[V any] struct {
+	value    V
+	expireAt time.Time // 为零时永不过期
+	timerId  uint64    // 过期定时器, 为0时没有
+}
+
+// 按LRU淘汰并支持过期的缓存, 过期由estimer定时器驱动, 并发安全.
+// 零值为不限容量, 默认永不过期的可用缓存
+type Cache[K comparable, V any] struct {
+	opts      CacheOptions
+	entries   OrderedMap[K, *cacheEntry[V]] // 最近使用的在最后
+	onEvict   func(key K, value V, reason EvictReason)
+	stats     CacheStats
+	ownTimers bool
+	closed    bool
+	lock      sync.Mutex
+}
+
+func NewCache[K comparable, V any](opts CacheOptions) *Cache[K, V] {
+	return &Cache[K, V]{opts: opts}
+}
+
+// 设置淘汰回调, 回调在不持有缓存锁时执行
+func (c *Cache[K, V]) SetEvictHandler(fn func(key K, value V, reason EvictReason)) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.onEvict = fn
+}
+
+// 读取并标记为最近使用
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.lock.Lock()
+	e, ok := c.entries.Get(key)
+	if ok && !e.expireAt.IsZero() && !time.Now().Before(e.expireAt) {
+		// 定时器还没来得及触发
+		c.remove(key, e)
+		c.stats.Expirations++
+		c.stats.Misses++
+		c.lock.Unlock()
+
+		c.evicted(key, e.value, EVICT_EXPIRED)
+		var zero V
+		return zero, false
+	}
+
+	if !ok {
+		c.stats.Misses++
+		c.lock.Unlock()
+		var zero V
+		return zero, false
+	}
+
+	c.stats.Hits++
+	c.entries.MoveToBack(key)
+	c.lock.Unlock()
+	return e.value, true
+}
+
+// 使用默认存活时间写入
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.opts.TTL)
+}
+
+// 写入并指定存活时间, ttl<=0为永不过期. Close之后不再写入
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	e := &cacheEntry[V]{value: value}
+
+	c.lock.Lock()
+	if c.closed {
+		c.lock.Unlock()
+		return
+	}
+
+	if old, ok := c.entries.Get(key); ok {
+		c.remove(key, old)
+	}
+
+	if ttl > 0 {
+		e.expireAt = time.Now().Add(ttl)
+		e.timerId, _ = c.timerQueue().NewTimer(ttl, false, func() {
+			c.expire(key, e)
+		})
+	}
+	c.entries.Set(key, e)
+
+	// 超出容量时淘汰最久未使用的
+	var victims []*cacheEntry[V]
+	var victimKeys []K
+	for c.opts.Capacity > 0 && c.entries.Len() > c.opts.Capacity {
+		k, v, _ := c.entries.Front()
+		c.remove(k, v)
+		c.stats.Evictions++
+		victims = append(victims, v)
+		victimKeys = append(victimKeys, k)
+	}
+	c.lock.Unlock()
+
+	for i, v := range victims {
+		c.evicted(victimKeys[i], v.value, EVICT_CAPACITY)
+	}
+}
+
+// 删除条目, 不会触发淘汰回调
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	e, ok := c.entries.Get(key)
+	if !ok {
+		return false
+	}
+	c.remove(key, e)
+	return true
+}
+
+func (c *Cache[K, V]) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.entries.Len()
+}
+
+func (c *Cache[K, V]) Stats() CacheStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	stats := c.stats
+	stats.Len = c.entries.Len()
+	return stats
+}
+
+// 清空缓存, 不会触发淘汰回调
+func (c *Cache[K, V]) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for key, e := range c.entries.All() {
+		c.remove(key, e)
+	}
+}
+
+// 清空缓存, 并停止缓存自己创建的定时器队列, 之后的写入会被忽略
+func (c *Cache[K, V]) Close() {
+	c.lock.Lock()
+	if c.closed {
+		c.lock.Unlock()
+		return
+	}
+	c.closed = true
+	for key, e := range c.entries.All() {
+		c.remove(key, e)
+	}
+	c.lock.Unlock()
+
+	if c.ownTimers && c.opts.Timers != nil {
+		c.opts.Timers.StopTimerQueue()
+	}
+}
+
+// 定时器回调, 条目已被替换或删除时忽略
+func (c *Cache[K, V]) expire(key K, e *cacheEntry[V]) {
+	c.lock.Lock()
+	if cur, ok := c.entries.Get(key); !ok || cur != e {
+		c.lock.Unlock()
+		return
+	}
+
+	c.remove(key, e)
+	c.stats.Expirations++
+	c.lock.Unlock()
+
+	c.evicted(key, e.value, EVICT_EXPIRED)
+}
+
+// 需持有锁
+func (c *Cache[K, V]) timerQueue() *estimer.HeapTimerQueue {
+	if c.opts.Timers == nil {
+		c.opts.Timers = estimer.NewHeapTimerQueue()
+		c.ownTimers = true
+	}
+	return c.opts.Timers
+}
+
+// 删除条目及其定时器, 定时器会立即从队列中移除, 需持有锁
+func (c *Cache[K, V]) remove(key K, e *cacheEntry[V]) {
+	if e.timerId != 0 {
+		c.opts.Timers.DeleteTimer(e.timerId)
+	}
+	c.entries.Delete(key)
+}
+
+func (c *Cache[K, V]) evicted(key K, value V, reason EvictReason) {
+	c.lock.Lock()
+	onEvict := c.onEvict
+	c.lock.Unlock()
+
+	if onEvict != nil {
+		onEvict(key, value, reason)
+	}
+
+	if c.opts.Dispatcher != nil {
+		eviction := &CacheEviction{Key: key, Value: value, Reason: reason}
+		c.opts.Dispatcher.DispatchEvent(NewEvent(c.opts.EvictEvent, c, eviction))
+	}
+}
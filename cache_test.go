@@ -0,0 +1,130 @@
+package goapl
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCacheLRU(t *testing.T) {
+	c := NewCache[string, int](CacheOptions{Capacity: 2})
+	defer c.Close()
+
+	var evicted []string
+	c.SetEvictHandler(func(key string, value int, reason EvictReason) {
+		if reason != EVICT_CAPACITY {
+			t.Errorf("ERR:reason %v", reason)
+		}
+		evicted = append(evicted, key)
+	})
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("ERR:get a = %v %v", v, ok)
+	}
+
+	// b最久未使用, 应被淘汰
+	c.Set("c", 3)
+	if _, ok := c.Get("b"); ok {
+		t.Error("ERR:b should be evicted")
+	}
+	if len(evicted) != 1 || evicted[0] != "b" {
+		t.Errorf("ERR:evicted %v", evicted)
+	}
+
+	if !c.Delete("a") || c.Delete("a") || c.Len() != 1 {
+		t.Error("ERR:delete wrong")
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Evictions != 1 || stats.Len != 1 {
+		t.Errorf("ERR:stats %+v", stats)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	c := NewCache[int, string](CacheOptions{TTL: 20 * time.Millisecond})
+	defer c.Close()
+
+	expired := make(chan int, 2)
+	c.SetEvictHandler(func(key int, value string, reason EvictReason) {
+		if reason == EVICT_EXPIRED {
+			expired <- key
+		}
+	})
+
+	c.Set(1, "one")
+	c.SetWithTTL(2, "two", 0)
+	c.Set(3, "three")
+	c.SetWithTTL(3, "three", time.Hour) // 替换后原来的定时器不应再生效
+
+	select {
+	case key := <-expired:
+		if key != 1 {
+			t.Errorf("ERR:expired key %d", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ERR:entry not expired")
+	}
+
+	if _, ok := c.Get(1); ok {
+		t.Error("ERR:1 should be expired")
+	}
+	if _, ok := c.Get(2); !ok {
+		t.Error("ERR:2 should never expire")
+	}
+	if _, ok := c.Get(3); !ok {
+		t.Error("ERR:3 should use the new ttl")
+	}
+
+	if stats := c.Stats(); stats.Expirations != 1 || stats.Len != 2 {
+		t.Errorf("ERR:stats %+v", stats)
+	}
+}
+
+func TestCacheEvictEvent(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	c := NewCache[string, int](CacheOptions{Capacity: 1, Dispatcher: dispatcher, EvictEvent: 7})
+	defer c.Close()
+
+	var mu sync.Mutex
+	var got *CacheEviction
+	dispatcher.On(7, func(evt *Event) error {
+		mu.Lock()
+		got = evt.Data.(*CacheEviction)
+		mu.Unlock()
+		return nil
+	})
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got == nil || got.Key != "a" || got.Value != 1 || got.Reason != EVICT_CAPACITY {
+		t.Errorf("ERR:eviction event %+v", got)
+	}
+}
+
+func TestCacheClose(t *testing.T) {
+	c := NewCache[int, int](CacheOptions{TTL: time.Hour})
+	c.Set(1, 1)
+	c.Close()
+	c.Close()
+
+	c.Set(2, 2)
+	if c.Len() != 0 {
+		t.Error("ERR:closed cache should ignore writes")
+	}
+}
+
+func TestCacheZeroValue(t *testing.T) {
+	var c Cache[string, int]
+	c.Set("a", 1)
+	c.SetWithTTL("b", 2, time.Hour)
+	if v, ok := c.Get("a"); !ok || v != 1 || c.Len() != 2 {
+		t.Error("ERR:zero value cache should be usable")
+	}
+	c.Close()
+}
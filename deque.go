@@ -0,0 +1,184 @@
+package goapl
+
+import "iter"
+
+// 环形缓冲区的最小容量, 容量总是2的幂
+const MIN_DEQUE_CAPACITY = 16
+
+// 基于环形缓冲区的双端队列, 两端的插入和删除都是O(1).
+// 元素满时容量翻倍, 元素个数降到容量的1/4时容量减半, 但不低于MIN_DEQUE_CAPACITY
+type Deque[T any] struct {
+	buf   []T
+	head  int
+	count int
+}
+
+func NewDeque[T any]() *Deque[T] {
+	return NewDequeSize[T](MIN_DEQUE_CAPACITY)
+}
+
+// 创建初始容量至少为capacity的双端队列
+func NewDequeSize[T any](capacity int) *Deque[T] {
+	size := MIN_DEQUE_CAPACITY
+	for size < capacity {
+		size <<= 1
+	}
+	return &Deque[T]{buf: make([]T, size)}
+}
+
+func (d *Deque[T]) PushBack(n T) {
+	d.grow()
+	d.buf[d.index(d.count)] = n
+	d.count++
+}
+
+func (d *Deque[T]) PushFront(n T) {
+	d.grow()
+	d.head = (d.head - 1) & (len(d.buf) - 1)
+	d.buf[d.head] = n
+	d.count++
+}
+
+func (d *Deque[T]) PopFront() (T, error) {
+	var zero T
+	if d.count == 0 {
+		return zero, ErrEmpty
+	}
+
+	n := d.buf[d.head]
+	d.buf[d.head] = zero
+	d.head = d.index(1)
+	d.count--
+	d.shrink()
+	return n, nil
+}
+
+func (d *Deque[T]) PopBack() (T, error) {
+	var zero T
+	if d.count == 0 {
+		return zero, ErrEmpty
+	}
+
+	tail := d.index(d.count - 1)
+	n := d.buf[tail]
+	d.buf[tail] = zero
+	d.count--
+	d.shrink()
+	return n, nil
+}
+
+func (d *Deque[T]) Front() (T, error) {
+	return d.At(0)
+}
+
+func (d *Deque[T]) Back() (T, error) {
+	return d.At(d.count - 1)
+}
+
+// 第index个元素, 0为队头
+func (d *Deque[T]) At(index int) (T, error) {
+	if index < 0 || index >= d.count {
+		var zero T
+		if d.count == 0 {
+			return zero, ErrEmpty
+		}
+		return zero, ErrIndexOutOfRange
+	}
+	return d.buf[d.index(index)], nil
+}
+
+func (d *Deque[T]) Len() int {
+	return d.count
+}
+
+func (d *Deque[T]) Cap() int {
+	return len(d.buf)
+}
+
+func (d *Deque[T]) Clear() {
+	clear(d.buf)
+	d.head = 0
+	d.count = 0
+	if len(d.buf) > MIN_DEQUE_CAPACITY {
+		d.buf = make([]T, MIN_DEQUE_CAPACITY)
+	}
+}
+
+// 从队头到队尾遍历, 遍历过程中不能修改队列
+func (d *Deque[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := 0; i < d.count; i++ {
+			if !yield(i, d.buf[d.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+func (d *Deque[T]) index(i int) int {
+	return (d.head + i) & (len(d.buf) - 1)
+}
+
+func (d *Deque[T]) grow() {
+	if d.buf == nil {
+		d.buf = make([]T, MIN_DEQUE_CAPACITY)
+	}
+	if d.count == len(d.buf) {
+		d.resize(len(d.buf) << 1)
+	}
+}
+
+func (d *Deque[T]) shrink() {
+	if len(d.buf) > MIN_DEQUE_CAPACITY && d.count <= len(d.buf)/4 {
+		d.resize(len(d.buf) >> 1)
+	}
+}
+
+func (d *Deque[T]) resize(size int) {
+	buf := make([]T, size)
+	if d.head+d.count <= len(d.buf) {
+		copy(buf, d.buf[d.head:d.head+d.count])
+	} else {
+		n := copy(buf, d.buf[d.head:])
+		copy(buf[n:], d.buf[:d.count-n])
+	}
+	d.buf = buf
+	d.head = 0
+}
+
+// 先进先出队列
+type Queue[T any] struct {
+	d Deque[T]
+}
+
+func NewQueue[T any]() *Queue[T] {
+	return &Queue[T]{d: *NewDeque[T]()}
+}
+
+// 放到队尾
+func (q *Queue[T]) Push(n T) {
+	q.d.PushBack(n)
+}
+
+// 取出队头
+func (q *Queue[T]) Pop() (T, error) {
+	return q.d.PopFront()
+}
+
+// 查看队头但不取出
+func (q *Queue[T]) Peek() (T, error) {
+	return q.d.Front()
+}
+
+func (q *Queue[T]) Len() int {
+	return q.d.Len()
+}
+
+func (q *Queue[T]) Clear() {
+	q.d.Clear()
+}
+
+// 从队头到队尾遍历, 遍历过程中不能修改队列
+func (q *Queue[T]) All() iter.Seq2[int, T] {
+	return q.d.All()
+}
@@ -0,0 +1,76 @@
+package goapl
+
+import "testing"
+
+func TestDeque(t *testing.T) {
+	d := NewDeque[int]()
+
+	if _, err := d.PopFront(); err != ErrEmpty {
+		t.Fatal("ERR:empty deque should return ErrEmpty")
+	}
+
+	// 两端交替插入, 触发扩容和环绕
+	for i := 0; i < 100; i++ {
+		d.PushBack(i)
+		d.PushFront(-i - 1)
+	}
+
+	if d.Len() != 200 || d.Cap() != 256 {
+		t.Fatalf("ERR:len should be 200 cap 256, got %d %d", d.Len(), d.Cap())
+	}
+
+	if n, _ := d.Front(); n != -100 {
+		t.Errorf("ERR:front should be -100, got %d", n)
+	}
+
+	if n, _ := d.Back(); n != 99 {
+		t.Errorf("ERR:back should be 99, got %d", n)
+	}
+
+	prev := -101
+	for i, n := range d.All() {
+		if n != prev+1 && !(prev == -1 && n == 0) {
+			t.Fatalf("ERR:element %d out of order: %d after %d", i, n, prev)
+		}
+		prev = n
+	}
+
+	if _, err := d.At(200); err != ErrIndexOutOfRange {
+		t.Error("ERR:index 200 should be out of range")
+	}
+
+	for i := 0; i < 190; i++ {
+		if i%2 == 0 {
+			d.PopFront()
+		} else {
+			d.PopBack()
+		}
+	}
+
+	if d.Len() != 10 || d.Cap() != 32 {
+		t.Errorf("ERR:deque should shrink, len %d cap %d", d.Len(), d.Cap())
+	}
+
+	if n, _ := d.Front(); n != -5 {
+		t.Errorf("ERR:front should be -5, got %d", n)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	q := NewQueue[string]()
+	q.Push("a")
+	q.Push("b")
+
+	if n, _ := q.Peek(); n != "a" || q.Len() != 2 {
+		t.Fatal("ERR:peek should return a")
+	}
+
+	if n, _ := q.Pop(); n != "a" {
+		t.Fatal("ERR:pop should return a")
+	}
+
+	q.Clear()
+	if _, err := q.Pop(); err != ErrEmpty {
+		t.Fatal("ERR:cleared queue should be empty")
+	}
+}
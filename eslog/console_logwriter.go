@@ -16,7 +16,7 @@ type ConsoleLogWriter struct {
 
 func DefaultConsoleLogWriter() *ConsoleLogWriter {
 	consoleWriter := &ConsoleLogWriter{
-		format: "%T %D|%C|%L|(%S) %M",
+		format: "%T %D|%C|%L|(%S) %I%M",
 		w:      make(chan *LogRecord, LogBufferLength),
 	}
 	go consoleWriter.run(stdout)
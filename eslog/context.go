@@ -0,0 +1,23 @@
+package eslog
+
+import "context"
+
+type traceIdKey struct{}
+
+// WithTraceID returns a copy of ctx carrying the trace id, which the *Context
+// logging methods write into each record (format code %I).
+func WithTraceID(ctx context.Context, traceId string) context.Context {
+	return context.WithValue(ctx, traceIdKey{}, traceId)
+}
+
+// TraceIDFromContext returns the trace id stored by WithTraceID, or "" if none.
+func TraceIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	if traceId, ok := ctx.Value(traceIdKey{}).(string); ok {
+		return traceId
+	}
+	return ""
+}
@@ -66,7 +66,7 @@ func NewFileLogWriter(fname string, rotate bool) *FileLogWriter {
 		rec:       make(chan *LogRecord, LogBufferLength),
 		rot:       make(chan bool),
 		filename:  fname,
-		format:    "[%D %T] [%L] (%S) %M",
+		format:    "[%D %T] [%L] (%S) %I%M",
 		rotate:    rotate,
 		maxbackup: 999,
 	}
@@ -1,6 +1,7 @@
 package eslog
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 	"time"
@@ -54,6 +55,7 @@ type LogRecord struct {
 	source   string    // The message source
 	message  string    // The log message
 	category string    // The category
+	traceId  string    // The trace id carried by context
 }
 
 type LogModuleInfo struct{
@@ -80,6 +82,16 @@ func (this *Logger) AddWriter(writer LogWriter) {
 	}
 }
 
+// Register a debug console command on the telnet writer. Returns false if no
+// TelnetLogWriter has been added. Commands print through RawPrintf.
+func (this *Logger) RegCommand(name string, mp interface{}, handler TelnetCmdFunc, usage string) bool {
+	if this.telnetWriter == nil {
+		return false
+	}
+	this.telnetWriter.RegCommand(name, mp, handler, usage)
+	return true
+}
+
 func (this *Logger) AddModule(name string, lvl level_t) {
 	this.modules[name] = &LogModuleInfo{
 		name:name,
@@ -130,7 +142,7 @@ func (this *Logger) RawPrintf(format string, args ...interface{}) {
 	}
 }
 
-func (this *Logger) levelPrintf(calldep int, moduleName string, level level_t, format string, args ...interface{}) {
+func (this *Logger) levelPrintf(calldep int, traceId string, moduleName string, level level_t, format string, args ...interface{}) {
 
 	//Check module
 	if _, ok := this.modules[moduleName]; !ok {
@@ -160,6 +172,7 @@ func (this *Logger) levelPrintf(calldep int, moduleName string, level level_t, f
 		source:   src,
 		message:  msg,
 		category: moduleName,
+		traceId:  traceId,
 	}
 
 	// Write log
@@ -173,29 +186,54 @@ func (this *Logger) levelPrintf(calldep int, moduleName string, level level_t, f
 //
 
 func (this *Logger) Fatal(name string, format string, args ...interface{}) {
-	this.levelPrintf(2, name, FATAL, format, args...)
+	this.levelPrintf(2, "", name, FATAL, format, args...)
 }
 
 func (this *Logger) Debug(name string, format string, args ...interface{}) {
-	this.levelPrintf(2, name, DEBUG, format, args...)
+	this.levelPrintf(2, "", name, DEBUG, format, args...)
 }
 
 func (this *Logger) Error(name string, format string, args ...interface{}) {
-	this.levelPrintf(2, name, ERROR, format, args...)
+	this.levelPrintf(2, "", name, ERROR, format, args...)
 }
 
 func (this *Logger) Warn(name string, format string, args ...interface{}) {
-	this.levelPrintf(2, name, WARN, format, args...)
+	this.levelPrintf(2, "", name, WARN, format, args...)
 }
 
 func (this *Logger) Info(name string, format string, args ...interface{}) {
-	this.levelPrintf(2, name, INFO, format, args...)
+	this.levelPrintf(2, "", name, INFO, format, args...)
 }
 
 func (this *Logger) Trace(name string, format string, args ...interface{}) {
-	this.levelPrintf(2, name, TRACE, format, args...)
+	this.levelPrintf(2, "", name, TRACE, format, args...)
+}
+
+// Context variants, the trace id stored by WithTraceID is written to the record
+
+func (this *Logger) FatalContext(ctx context.Context, name string, format string, args ...interface{}) {
+	this.levelPrintf(2, TraceIDFromContext(ctx), name, FATAL, format, args...)
+}
+
+func (this *Logger) DebugContext(ctx context.Context, name string, format string, args ...interface{}) {
+	this.levelPrintf(2, TraceIDFromContext(ctx), name, DEBUG, format, args...)
 }
 
+func (this *Logger) ErrorContext(ctx context.Context, name string, format string, args ...interface{}) {
+	this.levelPrintf(2, TraceIDFromContext(ctx), name, ERROR, format, args...)
+}
+
+func (this *Logger) WarnContext(ctx context.Context, name string, format string, args ...interface{}) {
+	this.levelPrintf(2, TraceIDFromContext(ctx), name, WARN, format, args...)
+}
+
+func (this *Logger) InfoContext(ctx context.Context, name string, format string, args ...interface{}) {
+	this.levelPrintf(2, TraceIDFromContext(ctx), name, INFO, format, args...)
+}
+
+func (this *Logger) TraceContext(ctx context.Context, name string, format string, args ...interface{}) {
+	this.levelPrintf(2, TraceIDFromContext(ctx), name, TRACE, format, args...)
+}
 
 //
 // Commands
@@ -1,6 +1,7 @@
 package eslog
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -51,3 +52,34 @@ func TestTelnetLogWriter(t *testing.T) {
 	log.Close()
 
 }
+
+type recordLogWriter struct {
+	lines []string
+}
+
+func (w *recordLogWriter) Name() string {
+	return "RecordLogWriter"
+}
+
+func (w *recordLogWriter) LogWrite(rec *LogRecord) {
+	w.lines = append(w.lines, FormatLogRecord("%L %I%M", rec))
+}
+
+func (w *recordLogWriter) Close() {
+}
+
+func TestLogContextTraceID(t *testing.T) {
+	writer := &recordLogWriter{}
+
+	log := NewLogger()
+	log.AddWriter(writer)
+	log.AddModule("Test", TRACE)
+
+	ctx := WithTraceID(context.Background(), "req-1")
+	log.InfoContext(ctx, "Test", "hello")
+	log.Info("Test", "plain")
+
+	if len(writer.lines) != 2 || writer.lines[0] != "INFO [req-1] hello\n" || writer.lines[1] != "INFO plain\n" {
+		t.Errorf("ERR:trace id should be formatted, got %q", writer.lines)
+	}
+}
@@ -7,7 +7,7 @@ import (
 )
 
 const (
-	FORMAT_DEFAULT = "[%D %T] [%L] (%S) %M"
+	FORMAT_DEFAULT = "[%D %T] [%L] (%S) %I%M"
 	FORMAT_SHORT   = "[%t %d] [%L] %M"
 	FORMAT_ABBREV  = "[%L] %M"
 )
@@ -27,9 +27,10 @@ var formatCache = &formatCacheType{}
 // %d - Date (01/02/06)
 // %L - Level (FNST, FINE, DEBG, TRAC, WARN, EROR, CRIT)
 // %S - Source
+// %I - Trace ID from the context, "[id] " or empty when not set
 // %M - Message
 // Ignores unknown formats
-// Recommended: "[%D %T] [%L] (%S) %M"
+// Recommended: "[%D %T] [%L] (%S) %I%M"
 func FormatLogRecord(format string, rec *LogRecord) string {
 	if rec == nil {
 		return "<nil>"
@@ -78,6 +79,10 @@ func FormatLogRecord(format string, rec *LogRecord) string {
 				out.WriteString(levelStrings[rec.level])
 			case 'S':
 				out.WriteString(rec.source)
+			case 'I':
+				if len(rec.traceId) > 0 {
+					out.WriteString("[" + rec.traceId + "] ")
+				}
 			case 'M':
 				out.WriteString(rec.message)
 			case 'C':
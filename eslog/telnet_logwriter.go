@@ -33,7 +33,7 @@ type TelnetLogWriter struct {
 // Constructor
 func NewTelnetLogWriter(port int16) *TelnetLogWriter {
 	c := &TelnetLogWriter{
-		format: "%T %D|%C|%L|(%S) %M",
+		format: "%T %D|%C|%L|(%S) %I%M",
 		chanRecord:make(chan *LogRecord),
 		localPort:port,
 		myCmds:make(map[string]*TelnetCmd),
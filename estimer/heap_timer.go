@@ -6,6 +6,7 @@ import (
 	"os"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -19,6 +20,7 @@ type Timer struct {
 	callback TimerCallback
 	repeat   bool
 	timerId  uint64
+	index    int // 在堆中的下标, 不在堆中时为-1
 }
 
 func (t *Timer) Cancel() {
@@ -60,16 +62,23 @@ func (h *_TimerHeap) Swap(i, j int) {
 	tmp = h.timers[i]
 	h.timers[i] = h.timers[j]
 	h.timers[j] = tmp
+	h.timers[i].index = i
+	h.timers[j].index = j
 }
 
 func (h *_TimerHeap) Push(x interface{}) {
-	h.timers = append(h.timers, x.(*Timer))
+	t := x.(*Timer)
+	t.index = len(h.timers)
+	h.timers = append(h.timers, t)
 }
 
 func (h *_TimerHeap) Pop() (ret interface{}) {
 	l := len(h.timers)
-	h.timers, ret = h.timers[:l-1], h.timers[l-1]
-	return
+	t := h.timers[l-1]
+	h.timers[l-1] = nil
+	h.timers = h.timers[:l-1]
+	t.index = -1
+	return t
 }
 
 //
@@ -81,7 +90,7 @@ type HeapTimerQueue struct {
 	timerHeapLock sync.Mutex
 	timeIdbase    uint64
 	timerTable    map[uint64]*Timer
-	isExit        bool
+	isExit        atomic.Bool
 	wg            sync.WaitGroup
 	ticker        *time.Ticker
 }
@@ -90,7 +99,6 @@ func NewHeapTimerQueue() *HeapTimerQueue {
 	timerQue := new(HeapTimerQueue)
 	heap.Init(&timerQue.timerHeap)
 	timerQue.timeIdbase = 1
-	timerQue.isExit = false
 	timerQue.wg.Add(1)
 	timerQue.timerTable = make(map[uint64]*Timer)
 
@@ -108,11 +116,11 @@ func (this *HeapTimerQueue) NewTimer(delay time.Duration, repeat bool, cb TimerC
 		repeat:   repeat,
 	}
 
+	this.timerHeapLock.Lock()
 	tid := this.timeIdbase
 	t.timerId = tid
 	this.timeIdbase++
 
-	this.timerHeapLock.Lock()
 	heap.Push(&this.timerHeap, t)
 	this.timerTable[tid] = t
 	this.timerHeapLock.Unlock()
@@ -121,18 +129,26 @@ func (this *HeapTimerQueue) NewTimer(delay time.Duration, repeat bool, cb TimerC
 }
 
 func (this *HeapTimerQueue) DeleteTimer(tid uint64) {
+	this.timerHeapLock.Lock()
+	defer this.timerHeapLock.Unlock()
 
-	if _, ok := this.timerTable[tid]; !ok {
+	t, ok := this.timerTable[tid]
+	if !ok {
 		return
 	}
 
-	this.timerTable[tid].Cancel()
+	// 从堆中移除, 避免已删除的定时器一直占用到原定的触发时间.
+	// 正在执行回调的重复定时器不在堆中, 清掉回调后不会再放回
+	t.Cancel()
+	if t.index >= 0 {
+		heap.Remove(&this.timerHeap, t.index)
+	}
+	delete(this.timerTable, tid)
 }
 
 func (this *HeapTimerQueue) StopTimerQueue() {
-	this.isExit = true
+	this.isExit.Store(true)
 	this.wg.Wait()
-	fmt.Println("StopTimerQueue Suc!")
 }
 
 // Tick once for timers
@@ -159,13 +175,14 @@ func (this *HeapTimerQueue) Tick() {
 
 		if !t.repeat {
 			t.callback = nil
+			delete(this.timerTable, t.timerId)
 		}
 
 		this.timerHeapLock.Unlock()
 		runCallback(callback)
 		this.timerHeapLock.Lock()
 
-		if t.repeat {
+		if t.repeat && t.callback != nil {
 			// add Timer back to heap
 			t.fireTime = t.fireTime.Add(t.interval)
 			if !t.fireTime.After(now) { // might happen when interval is very small
@@ -186,7 +203,7 @@ func (this *HeapTimerQueue) TimerLoop(tickInterval time.Duration) {
 	this.ticker = time.NewTicker(time.Millisecond)
 
 	for range this.ticker.C {
-		if this.isExit {
+		if this.isExit.Load() {
 			break
 		}
 
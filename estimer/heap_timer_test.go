@@ -126,6 +126,32 @@ func TestCancelTimer(t *testing.T) {
 	timer.StopTimerQueue()
 }
 
+func TestDeleteTimerRemovesFromHeap(t *testing.T) {
+	timer := NewHeapTimerQueue()
+
+	var tids []uint64
+	for i := 0; i < 100; i++ {
+		tid, _ := timer.NewTimer(time.Hour, false, func() {})
+		tids = append(tids, tid)
+	}
+	keep, _ := timer.NewTimer(time.Hour, false, func() {})
+
+	for _, tid := range tids {
+		timer.DeleteTimer(tid)
+	}
+
+	timer.timerHeapLock.Lock()
+	n := timer.timerHeap.Len()
+	top := timer.timerHeap.timers[0].timerId
+	timer.timerHeapLock.Unlock()
+	if n != 1 || top != keep {
+		t.Fatalf("deleted timers should be removed from heap, %v left", n)
+	}
+
+	// stop timer
+	timer.StopTimerQueue()
+}
+
 func NoTestTimerPerformance(t *testing.T) {
 	timer := NewHeapTimerQueue()
 	f, err := os.Create("TestTimerPerformance.cpuprof")
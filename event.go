@@ -0,0 +1,83 @@
+package goapl
+
+import (
+	"context"
+	"time"
+)
+
+// 事件对象, 派发时传递给每个监听器
+type Event struct {
+	Type   uint32      // 事件类型
+	Topic  string      // 事件主题, 如"timer.fired", 可为空
+	Source interface{} // 事件源, 为空时派发器填充为自身
+	Data   interface{} // 事件携带的数据
+	Time   time.Time   // 派发时间, 为空时派发器填充为当前时间
+
+	Bubbles       bool             // 是否冒泡到父派发器, NewEvent创建的事件默认冒泡
+	Phase         EventPhase       // 当前所处的派发阶段
+	Target        *EventDispatcher // 派发事件的派发器
+	CurrentTarget *EventDispatcher // 正在执行监听的派发器
+
+	stopped bool
+	typed   bool
+	ctx     context.Context
+	replies *eventReplies
+	remote  bool
+}
+
+// 事件派发阶段
+type EventPhase int
+
+const (
+	PHASE_NONE      EventPhase = iota
+	PHASE_CAPTURING            // 从根派发器向目标传递
+	PHASE_AT_TARGET            // 在目标派发器上
+	PHASE_BUBBLING             // 从目标向根派发器传递
+)
+
+// 带参数的事件监听函数, 返回错误会被汇总给派发者
+type EventListenerFunc func(evt *Event) error
+
+// 创建事件
+func NewEvent(eventType uint32, source interface{}, data interface{}) *Event {
+	return &Event{Type: eventType, Source: source, Data: data, Bubbles: true}
+}
+
+// 事件携带的context, 未设置时返回context.Background()
+func (this *Event) Context() context.Context {
+	if this.ctx == nil {
+		return context.Background()
+	}
+	return this.ctx
+}
+
+// 设置事件携带的context, ctx取消后不再执行后续监听. 通过eslog.WithTraceID设置的
+// trace id可在监听器中经由Logger的*Context方法输出
+func (this *Event) SetContext(ctx context.Context) *Event {
+	this.ctx = ctx
+	return this
+}
+
+// 事件是否由传输层从其他进程收到
+func (this *Event) IsRemote() bool {
+	return this.remote
+}
+
+// 清除上一次派发留下的状态, 同一个Event可以再次派发
+func (this *Event) reset() {
+	this.stopped = false
+	this.typed = false
+	this.Phase = PHASE_NONE
+	this.Target = nil
+	this.CurrentTarget = nil
+}
+
+// 停止事件继续派发给后续监听器, 也不再传递到其他派发器和传输层的对端. 只作用于本次派发
+func (this *Event) StopPropagation() {
+	this.stopped = true
+}
+
+// 事件是否已被停止派发, 请求的ctx已取消时也视为停止
+func (this *Event) IsPropagationStopped() bool {
+	return this.stopped || (this.ctx != nil && this.ctx.Err() != nil)
+}
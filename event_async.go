@@ -0,0 +1,173 @@
+package goapl
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrAsyncStarted     = errors.New("goapl: async dispatch already started")
+	ErrAsyncNotStarted  = errors.New("goapl: async dispatch not started")
+	ErrDispatcherClosed = errors.New("goapl: dispatcher closed")
+	ErrEventDropped     = errors.New("goapl: event dropped")
+)
+
+// 异步派发配置
+type AsyncOptions struct {
+	Workers   int            // 派发goroutine个数, 默认1
+	QueueSize int            // 队列长度, 默认32
+	Overflow  OverflowPolicy // 队列满时的策略
+}
+
+// 异步派发队列, 由固定个数的worker消费
+type asyncQueue struct {
+	events  *BlockingQueue[*Event]
+	policy  OverflowPolicy
+	dropped func(evt *Event) // 记录被丢弃的事件
+	pending int              // 已入队但还未派发完的事件个数
+	lock    sync.Mutex
+	cond    *sync.Cond
+	wg      sync.WaitGroup
+}
+
+// 开启异步派发模式, 之后可使用TriggerAsync
+func (this *EventDispatcher) StartAsync(opts AsyncOptions) error {
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 32
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if this.async != nil {
+		return ErrAsyncStarted
+	}
+
+	q := &asyncQueue{events: NewBlockingQueue[*Event](opts.QueueSize, opts.Overflow), policy: opts.Overflow}
+	q.cond = sync.NewCond(&q.lock)
+	q.dropped = func(evt *Event) {
+		this.counters(evt).dropped.Add(1)
+		q.done()
+	}
+	q.events.SetDropHandler(q.dropped)
+
+	q.wg.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		go q.worker(this)
+	}
+
+	this.async = q
+	return nil
+}
+
+// 异步派发事件, 事件放入队列后立即返回. OVERFLOW_BLOCK时队列满会等待, 直到evt的context取消
+func (this *EventDispatcher) TriggerAsync(evt *Event) error {
+	q := this.asyncQueue()
+	if q == nil {
+		return ErrAsyncNotStarted
+	}
+
+	if evt.Source == nil {
+		evt.Source = this
+	}
+	return q.put(evt, true)
+}
+
+// 等待队列中以及正在派发的事件全部完成, 不能在监听器中调用
+func (this *EventDispatcher) Flush() {
+	if q := this.asyncQueue(); q != nil {
+		q.flush()
+	}
+}
+
+// 关闭派发器, 停止延时派发, 不再接受异步事件, 并等待已入队的事件派发完毕
+func (this *EventDispatcher) Close() {
+	this.lock.Lock()
+	this.closed = true
+	this.lock.Unlock()
+
+	this.stopTimers()
+	if q := this.asyncQueue(); q != nil {
+		q.close()
+	}
+}
+
+func (this *EventDispatcher) asyncQueue() *asyncQueue {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.async
+}
+
+// 放入事件, wait为false时OVERFLOW_BLOCK队列满也不等待, 直接丢弃该事件
+func (q *asyncQueue) put(evt *Event, wait bool) error {
+	// 先计数, 避免worker在计数前就派发完
+	q.lock.Lock()
+	q.pending++
+	q.lock.Unlock()
+
+	var err error
+	if wait {
+		err = q.events.Put(evt.Context(), evt)
+	} else {
+		err = q.events.TryPut(evt)
+	}
+
+	switch err {
+	case nil:
+		return nil
+	case ErrQueueFull:
+		// 丢弃策略下被丢弃的新事件已经由drop回调减掉计数, OVERFLOW_BLOCK不会调用drop回调
+		if q.policy == OVERFLOW_BLOCK {
+			q.dropped(evt)
+		}
+		return ErrEventDropped
+	case ErrQueueClosed:
+		err = ErrDispatcherClosed
+	}
+
+	q.done()
+	return err
+}
+
+func (q *asyncQueue) len() int {
+	return q.events.Len()
+}
+
+func (q *asyncQueue) done() {
+	q.lock.Lock()
+	q.pending--
+	q.cond.Broadcast()
+	q.lock.Unlock()
+}
+
+func (q *asyncQueue) worker(dispatcher *EventDispatcher) {
+	defer q.wg.Done()
+
+	// 关闭后仍然把剩余事件派发完
+	for {
+		evt, err := q.events.Take(context.Background())
+		if err != nil {
+			return
+		}
+
+		dispatcher.DispatchEvent(evt)
+		q.done()
+	}
+}
+
+func (q *asyncQueue) flush() {
+	q.lock.Lock()
+	for q.pending > 0 {
+		q.cond.Wait()
+	}
+	q.lock.Unlock()
+}
+
+func (q *asyncQueue) close() {
+	q.events.Close()
+	q.wg.Wait()
+}
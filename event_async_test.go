@@ -0,0 +1,102 @@
+package goapl
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestTriggerAsync(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	if err := dispatcher.TriggerAsync(NewEvent(1, nil, nil)); err != ErrAsyncNotStarted {
+		t.Fatal("ERR:async should not be started")
+	}
+
+	var x int32
+	dispatcher.On(1, func(evt *Event) error {
+		atomic.AddInt32(&x, int32(evt.Data.(int)))
+		return nil
+	})
+
+	if err := dispatcher.StartAsync(AsyncOptions{Workers: 4, QueueSize: 8}); err != nil {
+		t.Fatal("ERR:StartAsync failed,", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if err := dispatcher.TriggerAsync(NewEvent(1, nil, 1)); err != nil {
+			t.Fatal("ERR:TriggerAsync failed,", err)
+		}
+	}
+
+	dispatcher.Flush()
+	if n := atomic.LoadInt32(&x); n != 100 {
+		t.Errorf("ERR:all events should be delivered, got %d", n)
+	}
+
+	dispatcher.Close()
+	if err := dispatcher.TriggerAsync(NewEvent(1, nil, 1)); err != ErrDispatcherClosed {
+		t.Error("ERR:closed dispatcher should reject events")
+	}
+}
+
+func TestTriggerAsyncOverflow(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var got []int
+	dispatcher.On(1, func(evt *Event) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		got = append(got, evt.Data.(int))
+		return nil
+	})
+
+	dispatcher.StartAsync(AsyncOptions{Workers: 1, QueueSize: 2, Overflow: OVERFLOW_DROP_OLDEST})
+
+	// 第一个事件被worker取走并阻塞在监听器中
+	dispatcher.TriggerAsync(NewEvent(1, nil, 0))
+	<-started
+
+	for i := 1; i <= 4; i++ {
+		if err := dispatcher.TriggerAsync(NewEvent(1, nil, i)); err != nil {
+			t.Fatal("ERR:drop oldest should accept new events,", err)
+		}
+	}
+
+	close(release)
+	dispatcher.Close()
+
+	if len(got) != 3 || got[0] != 0 || got[1] != 3 || got[2] != 4 {
+		t.Errorf("ERR:oldest events should be dropped, got %v", got)
+	}
+}
+
+func TestTriggerAsyncDropNewest(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	dispatcher.On(1, func(evt *Event) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return nil
+	})
+
+	dispatcher.StartAsync(AsyncOptions{Workers: 1, QueueSize: 1, Overflow: OVERFLOW_DROP_NEWEST})
+	dispatcher.TriggerAsync(NewEvent(1, nil, nil))
+	<-started
+
+	dispatcher.TriggerAsync(NewEvent(1, nil, nil))
+	if err := dispatcher.TriggerAsync(NewEvent(1, nil, nil)); err != ErrEventDropped {
+		t.Error("ERR:full queue should drop newest event")
+	}
+
+	close(release)
+	dispatcher.Close()
+}
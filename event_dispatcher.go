@@ -1,77 +1,484 @@
 package goapl
 
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sambios/goapl/estimer"
+)
 
 type EventHandlerFunc func()
 
-// 事件调度器中存放的单元
+// 监听句柄, 由 AddEventListener/On 返回, 用于移除监听
+type EventListener struct {
+	id         uint64
+	eventType  uint32
+	priority   int
+	once       bool
+	wildcard   bool
+	topic      string
+	capture    bool
+	filter     func(evt *Event) bool
+	fn         EventListenerFunc
+	dispatcher *EventDispatcher
+	removed    atomic.Bool
+}
+
+// 添加监听时的可选参数
+type ListenerOption func(listener *EventListener)
+
+// 设置监听优先级, 数值大的先执行, 相同优先级按添加顺序执行, 默认为0
+func WithPriority(priority int) ListenerOption {
+	return func(listener *EventListener) {
+		listener.priority = priority
+	}
+}
+
+// 设置过滤条件, 只有filter返回true的事件才会交给监听器
+func WithFilter(filter func(evt *Event) bool) ListenerOption {
+	return func(listener *EventListener) {
+		listener.filter = filter
+	}
+}
+
+// 监听在捕获阶段执行, 即在子派发器的监听之前收到事件, 而不是在冒泡阶段
+func WithCapture() ListenerOption {
+	return func(listener *EventListener) {
+		listener.capture = true
+	}
+}
+
+// 监听只执行一次, 第一次收到事件后自动取消
+func WithOnce() ListenerOption {
+	return func(listener *EventListener) {
+		listener.once = true
+	}
+}
+
+// 监听的唯一编号
+func (this *EventListener) ID() uint64 {
+	return this.id
+}
+
+// 监听的事件类型
+func (this *EventListener) EventType() uint32 {
+	return this.eventType
+}
+
+// 监听的优先级
+func (this *EventListener) Priority() int {
+	return this.priority
+}
+
+// 取消监听, 已经取消过返回false
+func (this *EventListener) Unsubscribe() bool {
+	return this.dispatcher.removeListener(this)
+}
+
+// 判断事件是否应交给监听器, 一次性监听在这里被消耗掉
+func (this *EventListener) accept(evt *Event) bool {
+	if this.removed.Load() {
+		return false
+	}
+
+	if this.filter != nil && !this.filter(evt) {
+		return false
+	}
+
+	// 并发派发时只有成功移除的一方执行
+	if this.once {
+		return this.dispatcher.removeListener(this)
+	}
+	return true
+}
+
+// 派发顺序, 优先级大的在前, 相同优先级先添加的在前
+func (this *EventListener) before(other *EventListener) bool {
+	if this.priority != other.priority {
+		return this.priority > other.priority
+	}
+	return this.id < other.id
+}
+
+// 事件调度器中存放的单元, Listeners按派发顺序排列, 只读
 type EventSaver struct {
-	evtid uint32
-	Listeners map[*EventHandlerFunc]bool
+	evtid     uint32
+	Listeners []*EventListener
+}
+
+// 按优先级插入监听, 修改时生成新的切片, 派发中的快照不受影响
+func (this *EventSaver) add(listener *EventListener) {
+	pos := sort.Search(len(this.Listeners), func(i int) bool {
+		return this.Listeners[i].priority < listener.priority
+	})
+
+	listeners := make([]*EventListener, 0, len(this.Listeners)+1)
+	listeners = append(listeners, this.Listeners[:pos]...)
+	listeners = append(listeners, listener)
+	listeners = append(listeners, this.Listeners[pos:]...)
+	this.Listeners = listeners
 }
 
-// 事件调度器基类
+func (this *EventSaver) remove(listener *EventListener) bool {
+	for i, l := range this.Listeners {
+		if l != listener {
+			continue
+		}
+
+		listeners := make([]*EventListener, 0, len(this.Listeners)-1)
+		listeners = append(listeners, this.Listeners[:i]...)
+		listeners = append(listeners, this.Listeners[i+1:]...)
+		this.Listeners = listeners
+		return true
+	}
+	return false
+}
+
+// 事件调度器基类, 可在多个goroutine中同时使用
 type EventDispatcher struct {
-	events map[uint32]EventSaver
+	events map[uint32]*EventSaver
+	all    EventSaver
+	topics EventSaver
+	idbase uint64
+	lock   sync.RWMutex
+	async  *asyncQueue
+	parent *EventDispatcher
+	closed bool
+
+	timers    *estimer.HeapTimerQueue
+	ownTimers bool
+	scheduled map[*ScheduledEvent]struct{} // 未触发或重复的延时派发, Close时取消
+
+	history map[uint32]*eventHistory
+	sticky  map[uint32]*Event
+
+	stats     map[statsKey]*eventCounters
+	statsLock sync.RWMutex
+
+	topicTypes map[string]uint32
+	typeTopics map[uint32]string
+
+	errorHandler EventErrorHandler
+	interceptors []EventInterceptor
+	transports   []attachedTransport
 }
 
-// 事件调度接口
+// 事件调度接口, 实现需保证并发安全, 且允许在监听器中添加或移除监听
 type IEventDispatcher interface {
+	AddEventListener(eventType uint32, fn EventHandlerFunc, opts ...ListenerOption) *EventListener
+
+	On(eventType uint32, fn EventListenerFunc, opts ...ListenerOption) *EventListener
 
-	AddEventListener(eventType uint32, fn EventHandlerFunc)
+	Once(eventType uint32, fn EventListenerFunc, opts ...ListenerOption) *EventListener
 
-	RemoveEventListener(eventType uint32, fn EventHandlerFunc) bool
+	OnAll(fn EventListenerFunc, opts ...ListenerOption) *EventListener
+
+	RemoveEventListener(eventType uint32, listener *EventListener) bool
+
+	RemoveAllListeners(eventType uint32) int
 
 	HasEventListener(eventType uint32) bool
 
+	ListenerCount(eventType uint32) int
+
+	OnTopic(pattern string, fn EventListenerFunc, opts ...ListenerOption) *EventListener
+
 	EventTrigger(eventType uint32) bool
+
+	DispatchEvent(evt *Event) error
+
+	DispatchEventContext(ctx context.Context, evt *Event) error
+
+	DispatchTopic(topic string, data interface{}) error
 }
 
 // 创建事件派发器
 func NewEventDispatcher() *EventDispatcher {
-	return &EventDispatcher{events:make(map[uint32]EventSaver)}
+	return &EventDispatcher{
+		events:     make(map[uint32]*EventSaver),
+		topicTypes: make(map[string]uint32),
+		typeTopics: make(map[uint32]string),
+		history:    make(map[uint32]*eventHistory),
+		sticky:     make(map[uint32]*Event),
+		stats:      make(map[statsKey]*eventCounters),
+		scheduled:  make(map[*ScheduledEvent]struct{}),
+	}
 }
 
-
 // 事件调度器添加事件
-func (this *EventDispatcher) AddEventListener(eventType uint32, handlerFunc EventHandlerFunc) {
-	if evt, ok := this.events[eventType]; ok {
-		evt.Listeners[&handlerFunc] = true
-		return
+func (this *EventDispatcher) AddEventListener(eventType uint32, handlerFunc EventHandlerFunc, opts ...ListenerOption) *EventListener {
+	return this.On(eventType, func(evt *Event) error {
+		handlerFunc()
+		return nil
+	}, opts...)
+}
+
+// 事件调度器添加带参数的事件监听, 粘性事件类型会立即收到最后一次派发的事件
+func (this *EventDispatcher) On(eventType uint32, fn EventListenerFunc, opts ...ListenerOption) *EventListener {
+	listener := this.newListener(fn, opts)
+	listener.eventType = eventType
+
+	this.lock.Lock()
+	this.idbase++
+	listener.id = this.idbase
+
+	evt, ok := this.events[eventType]
+	if !ok {
+		evt = &EventSaver{evtid: eventType}
+		this.events[eventType] = evt
 	}
+	evt.add(listener)
+	last := this.sticky[eventType]
+	this.lock.Unlock()
 
-	evt:= EventSaver{evtid:eventType, Listeners:make(map[*EventHandlerFunc]bool)}
-	this.events[eventType] = evt
-	evt.Listeners[&handlerFunc] = true
+	if last != nil {
+		this.deliverSticky(listener, last)
+	}
+	return listener
+}
 
+// 事件调度器添加只执行一次的监听
+func (this *EventDispatcher) Once(eventType uint32, fn EventListenerFunc, opts ...ListenerOption) *EventListener {
+	return this.On(eventType, fn, append(opts, WithOnce())...)
 }
 
-// 事件调度器移除某个监听
-func (this *EventDispatcher) RemoveEventListener(eventType uint32, listener EventHandlerFunc) bool {
-	if evt, ok := this.events[eventType]; ok {
-		delete(evt.Listeners, &listener)
+// 事件调度器添加监听所有事件类型的监听, 与普通监听一起按优先级和添加顺序执行.
+// 会立即按派发顺序收到每个粘性类型最后一次派发的事件
+func (this *EventDispatcher) OnAll(fn EventListenerFunc, opts ...ListenerOption) *EventListener {
+	listener := this.newListener(fn, opts)
+	listener.wildcard = true
+
+	this.lock.Lock()
+	this.idbase++
+	listener.id = this.idbase
+	this.all.add(listener)
+	sticky := this.stickyEvents(func(evt *Event) bool { return true })
+	this.lock.Unlock()
+
+	for _, last := range sticky {
+		this.deliverSticky(listener, last)
+	}
+	return listener
+}
+
+func (this *EventDispatcher) newListener(fn EventListenerFunc, opts []ListenerOption) *EventListener {
+	listener := &EventListener{
+		fn:         fn,
+		dispatcher: this,
+	}
+	for _, opt := range opts {
+		opt(listener)
+	}
+	return listener
+}
+
+// 事件调度器移除某个监听, 监听所有事件的监听只能通过Unsubscribe移除
+func (this *EventDispatcher) RemoveEventListener(eventType uint32, listener *EventListener) bool {
+	if listener == nil || listener.wildcard || listener.topic != "" || listener.eventType != eventType {
+		return false
+	}
+	return this.removeListener(listener)
+}
+
+func (this *EventDispatcher) removeListener(listener *EventListener) bool {
+	if listener == nil || listener.dispatcher != this {
+		return false
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if listener.wildcard || listener.topic != "" {
+		saver := &this.all
+		if listener.topic != "" {
+			saver = &this.topics
+		}
+		if !saver.remove(listener) {
+			return false
+		}
+		listener.removed.Store(true)
 		return true
 	}
 
-	return false
+	evt, ok := this.events[listener.eventType]
+	if !ok || !evt.remove(listener) {
+		return false
+	}
+
+	listener.removed.Store(true)
+	if len(evt.Listeners) == 0 {
+		delete(this.events, listener.eventType)
+	}
+	return true
+}
+
+// 事件调度器移除某个类型的所有监听, 返回移除的个数
+func (this *EventDispatcher) RemoveAllListeners(eventType uint32) int {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	evt, ok := this.events[eventType]
+	if !ok {
+		return 0
+	}
+
+	for _, listener := range evt.Listeners {
+		listener.removed.Store(true)
+	}
+	delete(this.events, eventType)
+	return len(evt.Listeners)
 }
 
 // 事件调度器是否包含某个类型的监听
 func (this *EventDispatcher) HasEventListener(eventType uint32) bool {
-	if _, ok := this.events[eventType]; ok {
-		return true
+	return this.ListenerCount(eventType) > 0
+}
+
+// 事件调度器中某个类型的监听个数
+func (this *EventDispatcher) ListenerCount(eventType uint32) int {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+
+	if evt, ok := this.events[eventType]; ok {
+		return len(evt.Listeners)
 	}
-	return false
+	return 0
 }
 
-// 事件调度器派发事件
+// 事件调度器派发事件, 没有任何监听时返回false
 func (this *EventDispatcher) EventTrigger(eventType uint32) bool {
-	for _, evt := range this.events {
-		if evt.evtid == eventType {
-			for listener := range evt.Listeners {
-				(*listener)()
-			}
-			return true
+	n, _ := this.intercept(NewEvent(eventType, this, nil))
+	return n > 0
+}
+
+// 事件调度器派发带数据的事件, 按优先级和添加顺序调用监听器.
+// 监听器返回错误或panic不会影响其他监听器, 全部失败信息以*DispatchError返回
+func (this *EventDispatcher) DispatchEvent(evt *Event) error {
+	if evt.Source == nil {
+		evt.Source = this
+	}
+
+	_, err := this.intercept(evt)
+	return err
+}
+
+// 派发携带context的事件, ctx取消后不再执行后续监听, 返回的错误包含ctx.Err()
+func (this *EventDispatcher) DispatchEventContext(ctx context.Context, evt *Event) error {
+	err := this.DispatchEvent(evt.SetContext(ctx))
+	return errors.Join(ctx.Err(), err)
+}
+
+// 在本派发器上执行某个阶段的监听, 返回参与派发的监听个数
+func (this *EventDispatcher) deliver(evt *Event, phase EventPhase, failures []*ListenerError) (int, []*ListenerError) {
+	listeners := this.snapshot(evt, phase)
+	if len(listeners) == 0 {
+		return 0, failures
+	}
+
+	counters := this.counters(evt)
+	evt.CurrentTarget = this
+	evt.Phase = phase
+	for _, listener := range listeners {
+		// 派发过程中被移除或被过滤的监听不再调用
+		if !listener.accept(evt) {
+			continue
+		}
+
+		start := time.Now()
+		lerr := runListener(listener, evt)
+		counters.observe(time.Since(start), lerr)
+
+		if lerr != nil {
+			failures = append(failures, lerr)
+			this.handleError(evt, lerr)
+		}
+
+		if evt.IsPropagationStopped() {
+			break
 		}
 	}
-	return false
+
+	return len(listeners), failures
+}
+
+// 根据BindTopic补全事件的Type或Topic, 只在目标派发器上执行一次.
+// 返回该事件是否需要记录到历史中
+func (this *EventDispatcher) resolve(evt *Event) bool {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+
+	if evt.Time.IsZero() {
+		evt.Time = time.Now()
+	}
+
+	evt.typed = true
+	if evt.Topic == "" {
+		evt.Topic = this.typeTopics[evt.Type]
+	} else if evt.Type == 0 {
+		// 调用者已指定Type时保留, 只在未指定时由主题推导
+		evt.Type, evt.typed = this.topicTypes[evt.Topic]
+	}
+
+	if !evt.typed {
+		return false
+	}
+	_, sticky := this.sticky[evt.Type]
+	return sticky || this.history[evt.Type] != nil
+}
+
+// 取得事件在某个阶段的监听列表, 列表只读, 派发时不持有锁, 监听器中可以再次操作派发器
+func (this *EventDispatcher) snapshot(evt *Event, phase EventPhase) []*EventListener {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+
+	var listeners []*EventListener
+	if saver, ok := this.events[evt.Type]; ok && evt.typed {
+		listeners = saver.Listeners
+	}
+	listeners = mergeListeners(listeners, this.all.Listeners)
+
+	if evt.Topic != "" {
+		listeners = mergeListeners(listeners, this.topics.match(evt.Topic))
+	}
+
+	if phase == PHASE_AT_TARGET {
+		return listeners
+	}
+
+	// 捕获阶段只执行捕获监听, 冒泡阶段只执行普通监听
+	var phased []*EventListener
+	for _, listener := range listeners {
+		if listener.capture == (phase == PHASE_CAPTURING) {
+			phased = append(phased, listener)
+		}
+	}
+	return phased
+}
+
+// 合并两个已排序的监听列表, 其中一个为空时直接返回另一个
+func mergeListeners(a, b []*EventListener) []*EventListener {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+
+	listeners := make([]*EventListener, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if b[j].before(a[i]) {
+			listeners = append(listeners, b[j])
+			j++
+		} else {
+			listeners = append(listeners, a[i])
+			i++
+		}
+	}
+	listeners = append(listeners, a[i:]...)
+	return append(listeners, b[j:]...)
 }
@@ -1,8 +1,13 @@
 package goapl
 
 import (
+	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/sambios/goapl/eslog"
 )
 
 const HELLO_WORLD = "helloWorld"
@@ -17,7 +22,6 @@ func TestDispatcher(t *testing.T) {
 	})
 
 	time.Sleep(time.Second * 2)
-	//dispatcher.RemoveEventListener(HELLO_WORLD, listener)
 
 	dispatcher.EventTrigger(1)
 	if x != 2 {
@@ -26,3 +30,262 @@ func TestDispatcher(t *testing.T) {
 
 }
 
+func TestDispatchEventPayload(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	var got interface{}
+	var src interface{}
+	dispatcher.On(2, func(evt *Event) error {
+		got = evt.Data
+		src = evt.Source
+		return nil
+	})
+
+	if err := dispatcher.DispatchEvent(NewEvent(2, nil, "payload")); err != nil {
+		t.Fatal("ERR:dispatch should not fail,", err)
+	}
+
+	if got != "payload" {
+		t.Error("ERR:listener should receive payload")
+	}
+
+	if src != dispatcher {
+		t.Error("ERR:source should default to dispatcher")
+	}
+}
+
+func TestDispatchEventErrorAndStop(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	errFailed := errors.New("failed")
+
+	calls := 0
+	dispatcher.On(3, func(evt *Event) error {
+		calls++
+		evt.StopPropagation()
+		return errFailed
+	})
+	dispatcher.On(3, func(evt *Event) error {
+		calls++
+		evt.StopPropagation()
+		return errFailed
+	})
+
+	err := dispatcher.DispatchEvent(NewEvent(3, nil, nil))
+	if !errors.Is(err, errFailed) {
+		t.Error("ERR:listener error should be returned")
+	}
+
+	if calls != 1 {
+		t.Errorf("ERR:stopped event should reach 1 listener, got %d", calls)
+	}
+}
+
+func TestDispatchSameEventTwice(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	calls := 0
+	dispatcher.On(3, func(evt *Event) error {
+		calls++
+		evt.StopPropagation()
+		return nil
+	})
+
+	evt := NewEvent(3, nil, nil)
+	dispatcher.DispatchEvent(evt)
+	dispatcher.DispatchEvent(evt)
+
+	if calls != 2 || evt.Phase != PHASE_NONE || evt.Target != dispatcher {
+		t.Errorf("ERR:event should be delivered again, calls=%d", calls)
+	}
+}
+
+func TestRemoveEventListener(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	x := 0
+	listener := dispatcher.AddEventListener(1, func() {
+		x++
+	})
+	dispatcher.AddEventListener(1, func() {
+		x += 10
+	})
+
+	if dispatcher.ListenerCount(1) != 2 {
+		t.Fatal("ERR:listener count should be 2")
+	}
+
+	if !listener.Unsubscribe() {
+		t.Fatal("ERR:unsubscribe should succeed")
+	}
+
+	if listener.Unsubscribe() {
+		t.Error("ERR:second unsubscribe should fail")
+	}
+
+	dispatcher.EventTrigger(1)
+	if x != 10 {
+		t.Errorf("ERR:removed listener should not run, x=%d", x)
+	}
+
+	if n := dispatcher.RemoveAllListeners(1); n != 1 {
+		t.Errorf("ERR:RemoveAllListeners should remove 1, got %d", n)
+	}
+
+	if dispatcher.HasEventListener(1) || dispatcher.EventTrigger(1) {
+		t.Error("ERR:no listener should remain")
+	}
+}
+
+func TestDispatcherConcurrent(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				listener := dispatcher.AddEventListener(1, func() {})
+				listener.Unsubscribe()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				dispatcher.EventTrigger(1)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestListenerModifyDispatcher(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	x := 0
+	var self *EventListener
+	self = dispatcher.AddEventListener(1, func() {
+		x++
+		self.Unsubscribe()
+		dispatcher.AddEventListener(2, func() {})
+	})
+
+	dispatcher.EventTrigger(1)
+	dispatcher.EventTrigger(1)
+	if x != 1 {
+		t.Errorf("ERR:listener should run once, x=%d", x)
+	}
+
+	if !dispatcher.HasEventListener(2) {
+		t.Error("ERR:listener added in callback should exist")
+	}
+}
+
+func TestListenerOrder(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	var order []int
+	for i := 0; i < 5; i++ {
+		i := i
+		dispatcher.AddEventListener(1, func() {
+			order = append(order, i)
+		})
+	}
+	dispatcher.AddEventListener(1, func() {
+		order = append(order, 100)
+	}, WithPriority(10))
+	dispatcher.AddEventListener(1, func() {
+		order = append(order, -1)
+	}, WithPriority(-1))
+
+	dispatcher.EventTrigger(1)
+
+	expect := []int{100, 0, 1, 2, 3, 4, -1}
+	if len(order) != len(expect) {
+		t.Fatalf("ERR:order should be %v, got %v", expect, order)
+	}
+	for i := range expect {
+		if order[i] != expect[i] {
+			t.Fatalf("ERR:order should be %v, got %v", expect, order)
+		}
+	}
+}
+
+func TestOnceAndFilter(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	once := 0
+	dispatcher.Once(1, func(evt *Event) error {
+		once++
+		return nil
+	}, WithFilter(func(evt *Event) bool {
+		return evt.Data == "go"
+	}))
+
+	dispatcher.DispatchEvent(NewEvent(1, nil, "wait"))
+	if once != 0 || dispatcher.ListenerCount(1) != 1 {
+		t.Fatal("ERR:filtered event should not consume once listener")
+	}
+
+	dispatcher.DispatchEvent(NewEvent(1, nil, "go"))
+	dispatcher.DispatchEvent(NewEvent(1, nil, "go"))
+	if once != 1 {
+		t.Errorf("ERR:once listener should run 1 time, got %d", once)
+	}
+
+	if dispatcher.HasEventListener(1) {
+		t.Error("ERR:once listener should be removed")
+	}
+}
+
+func TestOnAll(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	var seen []uint32
+	all := dispatcher.OnAll(func(evt *Event) error {
+		seen = append(seen, evt.Type)
+		return nil
+	})
+
+	if !dispatcher.EventTrigger(5) || !dispatcher.EventTrigger(6) {
+		t.Fatal("ERR:wildcard listener should receive every event")
+	}
+
+	if len(seen) != 2 || seen[0] != 5 || seen[1] != 6 {
+		t.Errorf("ERR:wildcard listener got %v", seen)
+	}
+
+	if !all.Unsubscribe() || dispatcher.EventTrigger(5) {
+		t.Error("ERR:wildcard listener should be removed")
+	}
+}
+
+func TestDispatchEventContext(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	ctx, cancel := context.WithCancel(eslog.WithTraceID(context.Background(), "req-1"))
+	defer cancel()
+
+	calls := 0
+	dispatcher.On(1, func(evt *Event) error {
+		calls++
+		if eslog.TraceIDFromContext(evt.Context()) != "req-1" {
+			t.Error("ERR:trace id should reach listener")
+		}
+		cancel()
+		return nil
+	})
+	dispatcher.On(1, func(evt *Event) error {
+		calls++
+		return nil
+	})
+
+	err := dispatcher.DispatchEventContext(ctx, NewEvent(1, nil, nil))
+	if !errors.Is(err, context.Canceled) {
+		t.Error("ERR:cancelled dispatch should return context error,", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("ERR:listeners after cancel should not run, calls=%d", calls)
+	}
+}
@@ -0,0 +1,109 @@
+package goapl
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/sambios/goapl/eslog"
+)
+
+// 单个监听器执行失败的信息
+type ListenerError struct {
+	Listener *EventListener
+	Err      error       // 监听器返回的错误, panic时为转换后的错误
+	Panic    interface{} // panic的值, 没有panic时为nil
+	Stack    []byte      // panic时的调用栈
+}
+
+func (e *ListenerError) Error() string {
+	return fmt.Sprintf("listener %d of event %d: %v", e.Listener.id, e.Listener.eventType, e.Err)
+}
+
+func (e *ListenerError) Unwrap() error {
+	return e.Err
+}
+
+// 是否由panic引起
+func (e *ListenerError) Paniced() bool {
+	return e.Panic != nil
+}
+
+// 一次派发中所有失败的监听器
+type DispatchError struct {
+	Event    *Event
+	Failures []*ListenerError
+}
+
+func (e *DispatchError) Error() string {
+	msgs := make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		msgs = append(msgs, failure.Error())
+	}
+	return fmt.Sprintf("dispatch event %d: %d listener(s) failed: %s",
+		e.Event.Type, len(e.Failures), strings.Join(msgs, "; "))
+}
+
+func (e *DispatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		errs = append(errs, failure)
+	}
+	return errs
+}
+
+// 监听器出错时的回调, 每个失败的监听器调用一次
+type EventErrorHandler func(evt *Event, err *ListenerError)
+
+// 设置监听器出错时的回调, 为nil时只把panic打印到标准错误
+func (this *EventDispatcher) SetErrorHandler(handler EventErrorHandler) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.errorHandler = handler
+}
+
+// 通过eslog记录监听器错误的回调
+func LogErrorHandler(logger *eslog.Logger, module string) EventErrorHandler {
+	return func(evt *Event, err *ListenerError) {
+		if err.Paniced() {
+			logger.ErrorContext(evt.Context(), module, "%v\n%s", err, err.Stack)
+			return
+		}
+		logger.ErrorContext(evt.Context(), module, "%v", err)
+	}
+}
+
+func (this *EventDispatcher) handleError(evt *Event, err *ListenerError) {
+	this.lock.RLock()
+	handler := this.errorHandler
+	this.lock.RUnlock()
+
+	if handler != nil {
+		handler(evt, err)
+		return
+	}
+
+	if err.Paniced() {
+		fmt.Fprintf(os.Stderr, "Listener %d of event %d paniced: %v\n%s", err.Listener.id, evt.Type, err.Panic, err.Stack)
+	}
+}
+
+// 执行监听器, 捕获panic使其不影响派发者和其他监听器
+func runListener(listener *EventListener, evt *Event) (lerr *ListenerError) {
+	defer func() {
+		if r := recover(); r != nil {
+			lerr = &ListenerError{
+				Listener: listener,
+				Err:      fmt.Errorf("panic: %v", r),
+				Panic:    r,
+				Stack:    debug.Stack(),
+			}
+		}
+	}()
+
+	if err := listener.fn(evt); err != nil {
+		return &ListenerError{Listener: listener, Err: err}
+	}
+	return nil
+}
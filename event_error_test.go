@@ -0,0 +1,49 @@
+package goapl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestListenerPanic(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	var handled []*ListenerError
+	dispatcher.SetErrorHandler(func(evt *Event, err *ListenerError) {
+		handled = append(handled, err)
+	})
+
+	errFailed := errors.New("failed")
+	x := 0
+	bad := dispatcher.AddEventListener(1, func() {
+		panic("boom")
+	})
+	failed := dispatcher.On(1, func(evt *Event) error {
+		return errFailed
+	})
+	dispatcher.AddEventListener(1, func() {
+		x++
+	})
+
+	err := dispatcher.DispatchEvent(NewEvent(1, nil, nil))
+	if x != 1 {
+		t.Error("ERR:listener after panic should still run")
+	}
+
+	var derr *DispatchError
+	if !errors.As(err, &derr) || len(derr.Failures) != 2 {
+		t.Fatal("ERR:dispatch should report 2 failures,", err)
+	}
+
+	if derr.Failures[0].Listener != bad || !derr.Failures[0].Paniced() {
+		t.Error("ERR:first failure should be the panic")
+	}
+
+	if derr.Failures[1].Listener != failed || !errors.Is(err, errFailed) {
+		t.Error("ERR:second failure should wrap listener error")
+	}
+
+	if len(handled) != 2 {
+		t.Errorf("ERR:error handler should be called twice, got %d", len(handled))
+	}
+}
@@ -0,0 +1,97 @@
+package goapl
+
+import (
+	"errors"
+	"sync"
+)
+
+var ErrDispatcherCycle = errors.New("goapl: dispatcher parent cycle")
+
+// 串行化所有父子关系的修改, 保证环检测和赋值之间关系链不会被其他goroutine改变
+var hierarchyLock sync.Mutex
+
+// 设置父派发器, 本派发器上的事件会经过父派发器的捕获和冒泡阶段. parent为nil时解除关系
+func (this *EventDispatcher) SetParent(parent *EventDispatcher) error {
+	hierarchyLock.Lock()
+	defer hierarchyLock.Unlock()
+
+	for p := parent; p != nil; p = p.Parent() {
+		if p == this {
+			return ErrDispatcherCycle
+		}
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.parent = parent
+	return nil
+}
+
+// 父派发器
+func (this *EventDispatcher) Parent() *EventDispatcher {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.parent
+}
+
+// 添加子派发器, 同child.SetParent(this)
+func (this *EventDispatcher) AddChild(child *EventDispatcher) error {
+	return child.SetParent(this)
+}
+
+// 移除子派发器, child不是本派发器的子派发器时返回false
+func (this *EventDispatcher) RemoveChild(child *EventDispatcher) bool {
+	hierarchyLock.Lock()
+	defer hierarchyLock.Unlock()
+
+	child.lock.Lock()
+	defer child.lock.Unlock()
+
+	if child.parent != this {
+		return false
+	}
+	child.parent = nil
+	return true
+}
+
+// 按捕获, 目标, 冒泡三个阶段传递事件, 返回参与派发的监听个数
+func (this *EventDispatcher) propagate(evt *Event) (int, error) {
+	if this.resolve(evt) {
+		this.record(evt)
+	}
+	this.counters(evt).triggered.Add(1)
+	evt.Target = this
+
+	// 父派发器也计入派发次数, 使其Delivered与Triggered口径一致
+	var path []*EventDispatcher
+	for p := this.Parent(); p != nil; p = p.Parent() {
+		p.counters(evt).triggered.Add(1)
+		path = append(path, p)
+	}
+
+	var failures []*ListenerError
+	total, n := 0, 0
+
+	for i := len(path) - 1; i >= 0 && !evt.IsPropagationStopped(); i-- {
+		n, failures = path[i].deliver(evt, PHASE_CAPTURING, failures)
+		total += n
+	}
+
+	if !evt.IsPropagationStopped() {
+		n, failures = this.deliver(evt, PHASE_AT_TARGET, failures)
+		total += n
+	}
+
+	for i := 0; evt.Bubbles && i < len(path) && !evt.IsPropagationStopped(); i++ {
+		n, failures = path[i].deliver(evt, PHASE_BUBBLING, failures)
+		total += n
+	}
+
+	evt.CurrentTarget = nil
+	evt.Phase = PHASE_NONE
+
+	if len(failures) > 0 {
+		return total, &DispatchError{Event: evt, Failures: failures}
+	}
+	return total, nil
+}
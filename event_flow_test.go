@@ -0,0 +1,92 @@
+package goapl
+
+import "testing"
+
+func TestEventBubbling(t *testing.T) {
+	root := NewEventDispatcher()
+	parent := NewEventDispatcher()
+	child := NewEventDispatcher()
+	root.AddChild(parent)
+	parent.AddChild(child)
+
+	var order []string
+	record := func(name string) EventListenerFunc {
+		return func(evt *Event) error {
+			if evt.Target != child {
+				t.Error("ERR:target should be child")
+			}
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	root.On(1, record("root-bubble"))
+	root.On(1, record("root-capture"), WithCapture())
+	parent.On(1, record("parent-bubble"))
+	parent.On(1, record("parent-capture"), WithCapture())
+	child.On(1, record("child"))
+
+	if !child.EventTrigger(1) {
+		t.Fatal("ERR:event should be delivered")
+	}
+
+	expect := []string{"root-capture", "parent-capture", "child", "parent-bubble", "root-bubble"}
+	if len(order) != len(expect) {
+		t.Fatalf("ERR:order should be %v, got %v", expect, order)
+	}
+	for i := range expect {
+		if order[i] != expect[i] {
+			t.Fatalf("ERR:order should be %v, got %v", expect, order)
+		}
+	}
+}
+
+func TestEventStopPropagation(t *testing.T) {
+	parent := NewEventDispatcher()
+	child := NewEventDispatcher()
+	child.SetParent(parent)
+
+	reached := false
+	parent.On(1, func(evt *Event) error {
+		reached = true
+		return nil
+	})
+	child.On(1, func(evt *Event) error {
+		evt.StopPropagation()
+		return nil
+	})
+
+	child.EventTrigger(1)
+	if reached {
+		t.Error("ERR:stopped event should not bubble")
+	}
+
+	// 不冒泡的事件只在目标上派发
+	child.RemoveAllListeners(1)
+	child.DispatchEvent(&Event{Type: 1})
+	if reached {
+		t.Error("ERR:non-bubbling event should not reach parent")
+	}
+
+	if parent.SetParent(child) != ErrDispatcherCycle {
+		t.Error("ERR:cycle should be rejected")
+	}
+
+	if !parent.RemoveChild(child) || child.Parent() != nil {
+		t.Error("ERR:child should be removed")
+	}
+}
+
+func TestSetParentConcurrentCycle(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		a, b := NewEventDispatcher(), NewEventDispatcher()
+
+		errs := make(chan error, 2)
+		go func() { errs <- a.SetParent(b) }()
+		go func() { errs <- b.SetParent(a) }()
+
+		if e1, e2 := <-errs, <-errs; e1 == nil && e2 == nil {
+			t.Fatal("ERR:concurrent SetParent should not form a cycle")
+		}
+	}
+}
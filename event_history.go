@@ -0,0 +1,161 @@
+package goapl
+
+import (
+	"slices"
+	"time"
+)
+
+// 某个事件类型最近的事件, 环形缓冲区
+type eventHistory struct {
+	events []*Event
+	start  int
+	count  int
+}
+
+func (h *eventHistory) push(evt *Event) {
+	size := len(h.events)
+	if h.count < size {
+		h.events[(h.start+h.count)%size] = evt
+		h.count++
+		return
+	}
+
+	h.events[h.start] = evt
+	h.start = (h.start + 1) % size
+}
+
+func (h *eventHistory) since(t time.Time) []*Event {
+	var events []*Event
+	for i := 0; i < h.count; i++ {
+		evt := h.events[(h.start+i)%len(h.events)]
+		if !evt.Time.Before(t) {
+			events = append(events, evt)
+		}
+	}
+	return events
+}
+
+// 复制事件, 避免调用者修改记录中的事件
+func copyEvents(events []*Event) []*Event {
+	if events == nil {
+		return nil
+	}
+
+	copies := make([]*Event, len(events))
+	for i, evt := range events {
+		c := *evt
+		copies[i] = &c
+	}
+	return copies
+}
+
+// 记录某个事件类型最近的size个事件, 供Replay使用. size<=0时关闭记录并清空
+func (this *EventDispatcher) EnableHistory(eventType uint32, size int) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if size <= 0 {
+		delete(this.history, eventType)
+		return
+	}
+
+	h := &eventHistory{events: make([]*Event, size)}
+	if old, ok := this.history[eventType]; ok {
+		for _, evt := range old.since(time.Time{}) {
+			h.push(evt)
+		}
+	}
+	this.history[eventType] = h
+}
+
+// 设置粘性事件, 新添加的监听会立即收到该类型最后一次派发的事件.
+// On按类型, OnTopic按事件的主题匹配, OnAll会收到所有粘性类型的最后一个事件
+func (this *EventDispatcher) SetSticky(eventType uint32, sticky bool) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if !sticky {
+		delete(this.sticky, eventType)
+		return
+	}
+
+	if _, ok := this.sticky[eventType]; !ok {
+		this.sticky[eventType] = nil
+	}
+}
+
+// 粘性事件最后一次派发的事件的副本, 没有时返回nil
+func (this *EventDispatcher) StickyEvent(eventType uint32) *Event {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+
+	if last := this.sticky[eventType]; last != nil {
+		evt := *last
+		return &evt
+	}
+	return nil
+}
+
+// 返回since之后(含)记录的事件的副本, 按派发顺序排列. 需先调用EnableHistory
+func (this *EventDispatcher) Replay(eventType uint32, since time.Time) []*Event {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+
+	if h, ok := this.history[eventType]; ok {
+		return copyEvents(h.since(since))
+	}
+	return nil
+}
+
+// 记录事件的副本, 不受调用者之后修改的影响, 也不保留派发过程中的状态
+func (this *EventDispatcher) record(evt *Event) {
+	c := *evt
+	c.stopped = false
+	c.ctx = nil
+	c.replies = nil
+	c.Phase = PHASE_NONE
+	c.CurrentTarget = nil
+	evt = &c
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if h, ok := this.history[evt.Type]; ok {
+		h.push(evt)
+	}
+
+	if _, ok := this.sticky[evt.Type]; ok {
+		this.sticky[evt.Type] = evt
+	}
+}
+
+// 找出满足match的粘性事件, 按派发时间排列. 需持有锁
+func (this *EventDispatcher) stickyEvents(match func(evt *Event) bool) []*Event {
+	var events []*Event
+	for _, evt := range this.sticky {
+		if evt != nil && match(evt) {
+			events = append(events, evt)
+		}
+	}
+
+	slices.SortFunc(events, func(a, b *Event) int {
+		return a.Time.Compare(b.Time)
+	})
+	return events
+}
+
+// 把粘性事件单独派发给新添加的监听
+func (this *EventDispatcher) deliverSticky(listener *EventListener, last *Event) {
+	evt := *last
+	evt.stopped = false
+	evt.Phase = PHASE_AT_TARGET
+	evt.CurrentTarget = this
+
+	if !listener.accept(&evt) {
+		return
+	}
+
+	if lerr := runListener(listener, &evt); lerr != nil {
+		this.handleError(&evt, lerr)
+	}
+}
@@ -0,0 +1,115 @@
+package goapl
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventHistory(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	dispatcher.EnableHistory(1, 3)
+
+	for i := 0; i < 5; i++ {
+		dispatcher.DispatchEvent(NewEvent(1, nil, i))
+	}
+
+	events := dispatcher.Replay(1, time.Time{})
+	if len(events) != 3 || events[0].Data != 2 || events[2].Data != 4 {
+		t.Fatalf("ERR:history should keep last 3 events, got %d", len(events))
+	}
+
+	since := events[2].Time
+	dispatcher.DispatchEvent(NewEvent(1, nil, 5))
+	events = dispatcher.Replay(1, since)
+	if len(events) != 2 || events[1].Data != 5 {
+		t.Errorf("ERR:replay since should return 2 events, got %d", len(events))
+	}
+
+	if dispatcher.Replay(2, time.Time{}) != nil {
+		t.Error("ERR:event without history should replay nothing")
+	}
+}
+
+func TestStickyEvent(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	dispatcher.SetSticky(1, true)
+
+	dispatcher.DispatchEvent(NewEvent(1, nil, "first"))
+	dispatcher.DispatchEvent(NewEvent(1, nil, "last"))
+
+	var got []interface{}
+	dispatcher.On(1, func(evt *Event) error {
+		got = append(got, evt.Data)
+		return nil
+	})
+
+	if len(got) != 1 || got[0] != "last" {
+		t.Fatalf("ERR:new listener should receive last sticky event, got %v", got)
+	}
+
+	dispatcher.DispatchEvent(NewEvent(1, nil, "next"))
+	if len(got) != 2 || got[1] != "next" {
+		t.Errorf("ERR:listener should keep receiving events, got %v", got)
+	}
+}
+
+func TestStickyTopicAndAll(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	dispatcher.BindTopic("config.changed", 1)
+	dispatcher.SetSticky(1, true)
+	dispatcher.SetSticky(2, true)
+
+	dispatcher.DispatchTopic("config.changed", "v1")
+	dispatcher.DispatchEvent(NewEvent(2, nil, "other"))
+
+	var topic []interface{}
+	dispatcher.OnTopic("config.*", func(evt *Event) error {
+		topic = append(topic, evt.Data)
+		return nil
+	})
+	if len(topic) != 1 || topic[0] != "v1" {
+		t.Errorf("ERR:topic listener should receive sticky event, got %v", topic)
+	}
+
+	var all []interface{}
+	dispatcher.OnAll(func(evt *Event) error {
+		all = append(all, evt.Data)
+		return nil
+	})
+	if len(all) != 2 || all[0] != "v1" || all[1] != "other" {
+		t.Errorf("ERR:OnAll should receive every sticky event, got %v", all)
+	}
+}
+
+func TestReplayReturnsCopies(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	dispatcher.EnableHistory(1, 2)
+	dispatcher.DispatchEvent(NewEvent(1, nil, "a"))
+
+	dispatcher.Replay(1, time.Time{})[0].Data = "changed"
+	if events := dispatcher.Replay(1, time.Time{}); events[0].Data != "a" {
+		t.Error("ERR:modifying replayed events should not change the history")
+	}
+}
+
+func TestHistoryKeepsCopy(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	dispatcher.EnableHistory(1, 2)
+	dispatcher.SetSticky(1, true)
+	dispatcher.On(1, func(evt *Event) error {
+		evt.StopPropagation()
+		return nil
+	})
+
+	evt := NewEvent(1, nil, "a")
+	dispatcher.DispatchEvent(evt)
+	evt.Data = "mutated"
+
+	sticky := dispatcher.StickyEvent(1)
+	if sticky.Data != "a" || sticky.IsPropagationStopped() || sticky.Phase != PHASE_NONE {
+		t.Errorf("ERR:sticky event should be a clean copy, %+v", sticky)
+	}
+	if events := dispatcher.Replay(1, time.Time{}); events[0].Data != "a" {
+		t.Error("ERR:history should not change with the dispatched event")
+	}
+}
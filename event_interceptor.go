@@ -0,0 +1,72 @@
+package goapl
+
+import (
+	"errors"
+
+	"github.com/sambios/goapl/eslog"
+)
+
+var ErrEventVetoed = errors.New("goapl: event vetoed")
+
+// 事件拦截器, 包裹一次派发. 可以修改evt后调用next, 也可以不调用next否决该事件,
+// 返回值作为派发的结果返回给派发者
+type EventInterceptor func(evt *Event, next func(evt *Event) error) error
+
+// 添加拦截器, 按添加顺序由外向内执行. 拦截器只作用于在本派发器上派发的事件
+func (this *EventDispatcher) Use(interceptors ...EventInterceptor) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	chain := make([]EventInterceptor, 0, len(this.interceptors)+len(interceptors))
+	chain = append(chain, this.interceptors...)
+	this.interceptors = append(chain, interceptors...)
+}
+
+// 经过拦截器链后传递事件, 返回参与派发的监听个数
+func (this *EventDispatcher) intercept(evt *Event) (int, error) {
+	evt.reset()
+
+	this.lock.RLock()
+	interceptors := this.interceptors
+	this.lock.RUnlock()
+
+	n := 0
+	next := func(evt *Event) error {
+		var err error
+		n, err = this.propagate(evt)
+
+		// 被本地监听停止或ctx已取消的事件不再转发给对端
+		if evt.IsPropagationStopped() {
+			return err
+		}
+		if ferr := this.forward(evt); ferr != nil {
+			return errors.Join(err, ferr)
+		}
+		return err
+	}
+
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := interceptors[i], next
+		next = func(evt *Event) error {
+			return interceptor(evt, inner)
+		}
+	}
+
+	err := next(evt)
+	return n, err
+}
+
+// 通过eslog以TRACE级别记录每个事件的拦截器
+func LogInterceptor(logger *eslog.Logger, module string) EventInterceptor {
+	return func(evt *Event, next func(evt *Event) error) error {
+		logger.TraceContext(evt.Context(), module, "event type=%d topic=%s source=%v data=%v",
+			evt.Type, evt.Topic, evt.Source, evt.Data)
+
+		err := next(evt)
+		if err != nil {
+			logger.TraceContext(evt.Context(), module, "event type=%d topic=%s failed: %v",
+				evt.Type, evt.Topic, err)
+		}
+		return err
+	}
+}
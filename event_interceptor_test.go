@@ -0,0 +1,64 @@
+package goapl
+
+import (
+	"testing"
+
+	"github.com/sambios/goapl/eslog"
+)
+
+func TestInterceptor(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	var order []string
+	dispatcher.Use(func(evt *Event, next func(evt *Event) error) error {
+		order = append(order, "outer")
+		return next(evt)
+	}, func(evt *Event, next func(evt *Event) error) error {
+		order = append(order, "inner")
+		if evt.Data == "bad" {
+			return ErrEventVetoed
+		}
+		evt.Data = evt.Data.(string) + "!"
+		return next(evt)
+	})
+
+	var got interface{}
+	dispatcher.On(1, func(evt *Event) error {
+		got = evt.Data
+		return nil
+	})
+
+	if err := dispatcher.DispatchEvent(NewEvent(1, nil, "hi")); err != nil {
+		t.Fatal("ERR:dispatch should succeed,", err)
+	}
+
+	if got != "hi!" || len(order) != 2 || order[0] != "outer" {
+		t.Errorf("ERR:interceptors should modify event in order, got %v %v", got, order)
+	}
+
+	got = nil
+	if err := dispatcher.DispatchEvent(NewEvent(1, nil, "bad")); err != ErrEventVetoed {
+		t.Error("ERR:vetoed event should return interceptor error,", err)
+	}
+
+	if got != nil {
+		t.Error("ERR:vetoed event should not reach listeners")
+	}
+}
+
+func TestLogInterceptor(t *testing.T) {
+	logger := eslog.NewLogger()
+	logger.AddModule("Event", eslog.TRACE)
+
+	dispatcher := NewEventDispatcher()
+	dispatcher.Use(LogInterceptor(logger, "Event"))
+
+	x := 0
+	dispatcher.AddEventListener(1, func() {
+		x++
+	})
+
+	if !dispatcher.EventTrigger(1) || x != 1 {
+		t.Error("ERR:log interceptor should pass event through")
+	}
+}
@@ -0,0 +1,238 @@
+package goapl
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/sambios/goapl/eslog"
+)
+
+// 监听执行耗时的统计区间上限, 最后一个区间统计超过1s的
+var LatencyBuckets = []time.Duration{
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+// 没有主题监听匹配的未绑定主题事件汇总统计在该键下
+const UNMATCHED_TOPIC_STATS = "**"
+
+// 统计的键, 数值事件按Type统计, 未绑定数值类型的主题事件按第一个匹配的主题模式统计,
+// 避免按ID等动态生成的主题使统计无限增长
+type statsKey struct {
+	eventType uint32
+	topic     string
+}
+
+func (this *EventDispatcher) statsKeyOf(evt *Event) statsKey {
+	if evt.Type != 0 || evt.Topic == "" {
+		return statsKey{eventType: evt.Type}
+	}
+
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+
+	for _, listener := range this.topics.Listeners {
+		if MatchTopic(listener.topic, evt.Topic) {
+			return statsKey{topic: listener.topic}
+		}
+	}
+	return statsKey{topic: UNMATCHED_TOPIC_STATS}
+}
+
+// 某个事件的计数器
+type eventCounters struct {
+	triggered atomic.Uint64
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+	failed    atomic.Uint64
+	panicked  atomic.Uint64
+	latency   []atomic.Uint64
+	latencyNs atomic.Int64
+}
+
+func (c *eventCounters) observe(cost time.Duration, lerr *ListenerError) {
+	c.delivered.Add(1)
+	c.latencyNs.Add(int64(cost))
+
+	bucket := sort.Search(len(LatencyBuckets), func(i int) bool {
+		return cost <= LatencyBuckets[i]
+	})
+	c.latency[bucket].Add(1)
+
+	if lerr != nil {
+		c.failed.Add(1)
+		if lerr.Paniced() {
+			c.panicked.Add(1)
+		}
+	}
+}
+
+// 监听耗时分布, Counts[i]为耗时不超过Bounds[i]的次数, 最后一项为超过所有Bounds的次数
+type LatencyHistogram struct {
+	Bounds []time.Duration
+	Counts []uint64
+	Total  time.Duration
+}
+
+// 平均耗时
+func (h LatencyHistogram) Mean() time.Duration {
+	var n uint64
+	for _, c := range h.Counts {
+		n += c
+	}
+	if n == 0 {
+		return 0
+	}
+	return h.Total / time.Duration(n)
+}
+
+// 单个事件的统计
+type EventStats struct {
+	Type      uint32
+	Topic     string // 数值事件绑定的主题, 或未绑定主题事件的主题模式
+	Listeners int    // 当前的监听个数
+	Triggered uint64 // 派发次数, 父派发器上包括子派发器派发并经过捕获或冒泡阶段的次数
+	Delivered uint64 // 监听执行次数
+	Dropped   uint64 // 异步队列满时丢弃的次数
+	Failed    uint64 // 监听返回错误或panic的次数
+	Panicked  uint64 // 监听panic的次数
+	Latency   LatencyHistogram
+}
+
+// 派发器的统计快照
+type DispatcherStats struct {
+	Events         []EventStats   // 按Type, Topic排序
+	TopicListeners map[string]int // 各主题模式的监听个数
+	AllListeners   int            // OnAll的监听个数
+	QueueLength    int            // 异步队列中等待派发的事件个数
+}
+
+func (s DispatcherStats) String() string {
+	out := bytes.NewBuffer(nil)
+	fmt.Fprintf(out, "%-10s %-20s %8s %10s %10s %8s %8s %8s %10s\n",
+		"type", "topic", "listener", "triggered", "delivered", "dropped", "failed", "panicked", "mean")
+	for _, e := range s.Events {
+		fmt.Fprintf(out, "%-10d %-20s %8d %10d %10d %8d %8d %8d %10s\n",
+			e.Type, e.Topic, e.Listeners, e.Triggered, e.Delivered, e.Dropped, e.Failed, e.Panicked, e.Latency.Mean())
+	}
+	fmt.Fprintf(out, "all listeners: %d, topic patterns: %d, queue length: %d\n",
+		s.AllListeners, len(s.TopicListeners), s.QueueLength)
+	return out.String()
+}
+
+// 取得派发器的统计快照, 包括所有注册过监听或派发过的事件
+func (this *EventDispatcher) Stats() DispatcherStats {
+	events := make(map[statsKey]*EventStats)
+
+	this.statsLock.RLock()
+	for key, c := range this.stats {
+		e := &EventStats{
+			Type:      key.eventType,
+			Topic:     key.topic,
+			Triggered: c.triggered.Load(),
+			Delivered: c.delivered.Load(),
+			Dropped:   c.dropped.Load(),
+			Failed:    c.failed.Load(),
+			Panicked:  c.panicked.Load(),
+			Latency: LatencyHistogram{
+				Bounds: LatencyBuckets,
+				Counts: make([]uint64, len(c.latency)),
+				Total:  time.Duration(c.latencyNs.Load()),
+			},
+		}
+		for i := range c.latency {
+			e.Latency.Counts[i] = c.latency[i].Load()
+		}
+		events[key] = e
+	}
+	this.statsLock.RUnlock()
+
+	stats := DispatcherStats{TopicListeners: make(map[string]int)}
+
+	this.lock.RLock()
+	for eventType, saver := range this.events {
+		key := statsKey{eventType: eventType}
+		if _, ok := events[key]; !ok {
+			events[key] = &EventStats{Type: eventType}
+		}
+		events[key].Listeners = len(saver.Listeners)
+	}
+	for key, e := range events {
+		if key.topic == "" {
+			e.Topic = this.typeTopics[key.eventType]
+		}
+	}
+	for _, listener := range this.topics.Listeners {
+		stats.TopicListeners[listener.topic]++
+	}
+	stats.AllListeners = len(this.all.Listeners)
+	q := this.async
+	this.lock.RUnlock()
+
+	if q != nil {
+		stats.QueueLength = q.len()
+	}
+
+	for _, e := range events {
+		stats.Events = append(stats.Events, *e)
+	}
+	sort.Slice(stats.Events, func(i, j int) bool {
+		if stats.Events[i].Type != stats.Events[j].Type {
+			return stats.Events[i].Type < stats.Events[j].Type
+		}
+		return stats.Events[i].Topic < stats.Events[j].Topic
+	})
+	return stats
+}
+
+// 清空所有计数
+func (this *EventDispatcher) ResetStats() {
+	this.statsLock.Lock()
+	defer this.statsLock.Unlock()
+	this.stats = make(map[statsKey]*eventCounters)
+}
+
+// 在logger的telnet调试控制台注册名为name的命令, 输出派发器的统计.
+// logger还没有添加TelnetLogWriter时返回false
+func (this *EventDispatcher) RegDebugCommand(logger *eslog.Logger, name string) bool {
+	return logger.RegCommand(name, &debugTarget{dispatcher: this, logger: logger}, dbgEventStats,
+		"Show event dispatcher stats.")
+}
+
+type debugTarget struct {
+	dispatcher *EventDispatcher
+	logger     *eslog.Logger
+}
+
+func dbgEventStats(args ...interface{}) {
+	c := args[0].(*debugTarget)
+	c.logger.RawPrintf("%s", c.dispatcher.Stats().String())
+}
+
+func (this *EventDispatcher) counters(evt *Event) *eventCounters {
+	key := this.statsKeyOf(evt)
+
+	this.statsLock.RLock()
+	c, ok := this.stats[key]
+	this.statsLock.RUnlock()
+	if ok {
+		return c
+	}
+
+	this.statsLock.Lock()
+	defer this.statsLock.Unlock()
+
+	c, ok = this.stats[key]
+	if !ok {
+		c = &eventCounters{latency: make([]atomic.Uint64, len(LatencyBuckets)+1)}
+		this.stats[key] = c
+	}
+	return c
+}
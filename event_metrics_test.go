@@ -0,0 +1,105 @@
+package goapl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDispatcherStats(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	dispatcher.BindTopic("timer.fired", 1)
+
+	dispatcher.AddEventListener(1, func() {})
+	dispatcher.AddEventListener(1, func() {
+		panic("boom")
+	})
+	dispatcher.SetErrorHandler(func(evt *Event, err *ListenerError) {})
+	dispatcher.OnTopic("log.*", func(evt *Event) error {
+		return nil
+	})
+
+	dispatcher.EventTrigger(1)
+	dispatcher.EventTrigger(1)
+	dispatcher.DispatchTopic("log.write", nil)
+
+	stats := dispatcher.Stats()
+	if len(stats.Events) != 2 {
+		t.Fatalf("ERR:stats should have 2 events, got %d", len(stats.Events))
+	}
+
+	// 主题事件的Type为0, 排在前面
+	e := stats.Events[1]
+	if e.Type != 1 || e.Topic != "timer.fired" || e.Listeners != 2 {
+		t.Errorf("ERR:event 1 stats wrong, %+v", e)
+	}
+
+	if e.Triggered != 2 || e.Delivered != 4 || e.Failed != 2 || e.Panicked != 2 {
+		t.Errorf("ERR:event 1 counters wrong, %+v", e)
+	}
+
+	var n uint64
+	for _, c := range e.Latency.Counts {
+		n += c
+	}
+	if n != 4 {
+		t.Errorf("ERR:latency histogram should count 4 deliveries, got %d", n)
+	}
+
+	if stats.Events[0].Topic != "log.*" || stats.Events[0].Delivered != 1 {
+		t.Errorf("ERR:topic stats wrong, %+v", stats.Events[0])
+	}
+
+	if stats.TopicListeners["log.*"] != 1 {
+		t.Error("ERR:topic listener count should be 1")
+	}
+
+	if !strings.Contains(stats.String(), "timer.fired") {
+		t.Error("ERR:stats text should list topics")
+	}
+
+	dispatcher.ResetStats()
+	stats = dispatcher.Stats()
+	if len(stats.Events) != 1 || stats.Events[0].Listeners != 2 || stats.Events[0].Triggered != 0 {
+		t.Error("ERR:counters should be reset and listeners kept")
+	}
+}
+
+func TestTopicStatsByPattern(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	dispatcher.OnTopic("order.*", func(evt *Event) error {
+		return nil
+	})
+
+	for i := 0; i < 10; i++ {
+		dispatcher.DispatchTopic(fmt.Sprintf("order.%d", i), nil)
+		dispatcher.DispatchTopic(fmt.Sprintf("request.%d", i), nil)
+	}
+
+	stats := dispatcher.Stats()
+	if len(stats.Events) != 2 {
+		t.Fatalf("ERR:dynamic topics should be aggregated, got %d events", len(stats.Events))
+	}
+	for _, e := range stats.Events {
+		if e.Triggered != 10 {
+			t.Errorf("ERR:%s should be triggered 10 times, got %d", e.Topic, e.Triggered)
+		}
+	}
+	if stats.Events[0].Topic != UNMATCHED_TOPIC_STATS || stats.Events[1].Topic != "order.*" {
+		t.Errorf("ERR:topic stats keys wrong, %+v", stats.Events)
+	}
+}
+
+func TestParentStatsTriggered(t *testing.T) {
+	parent := NewEventDispatcher()
+	child := NewEventDispatcher()
+	parent.AddChild(child)
+	parent.AddEventListener(1, func() {})
+
+	child.EventTrigger(1)
+
+	e := parent.Stats().Events[0]
+	if e.Triggered != 1 || e.Delivered != 1 {
+		t.Errorf("ERR:parent should count bubbled events as triggered, %+v", e)
+	}
+}
@@ -0,0 +1,78 @@
+package goapl
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// 请求型事件的应答函数, 返回nil表示不处理该请求
+type EventResponderFunc func(evt *Event) (interface{}, error)
+
+// 请求结果的收集方式
+type RequestMode int
+
+const (
+	REQUEST_ALL   RequestMode = iota // 收集所有非nil的应答
+	REQUEST_FIRST                    // 收到第一个非nil应答后停止派发
+)
+
+// 请求事件的应答收集器
+type eventReplies struct {
+	mode    RequestMode
+	results []interface{}
+	lock    sync.Mutex
+}
+
+func (r *eventReplies) add(result interface{}) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.results = append(r.results, result)
+}
+
+func (r *eventReplies) snapshot() []interface{} {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]interface{}(nil), r.results...)
+}
+
+// 添加请求型事件的应答监听, 通过Request派发时返回值会被收集, 普通派发时返回值被忽略
+func (this *EventDispatcher) Respond(eventType uint32, fn EventResponderFunc, opts ...ListenerOption) *EventListener {
+	return this.On(eventType, func(evt *Event) error {
+		result, err := fn(evt)
+		if err != nil {
+			return err
+		}
+
+		if result == nil || evt.replies == nil {
+			return nil
+		}
+
+		evt.replies.add(result)
+		if evt.replies.mode == REQUEST_FIRST {
+			evt.StopPropagation()
+		}
+		return nil
+	}, opts...)
+}
+
+// 派发请求型事件并收集应答. ctx取消或超时后不再执行后续监听, 立即返回已收集的结果和ctx的错误.
+// 派发的是evt的副本, evt本身不会被修改. 超时时正在执行的监听会在后台goroutine中继续执行完,
+// 一直阻塞的监听会使该goroutine无法退出
+func (this *EventDispatcher) Request(ctx context.Context, evt *Event, mode RequestMode) ([]interface{}, error) {
+	req := *evt
+	req.replies = &eventReplies{mode: mode}
+	req.SetContext(ctx)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- this.DispatchEvent(&req)
+	}()
+
+	select {
+	case err := <-done:
+		return req.replies.snapshot(), errors.Join(ctx.Err(), err)
+	case <-ctx.Done():
+		return req.replies.snapshot(), ctx.Err()
+	}
+}
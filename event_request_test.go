@@ -0,0 +1,63 @@
+package goapl
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRequest(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	dispatcher.Respond(1, func(evt *Event) (interface{}, error) {
+		return nil, nil
+	})
+	dispatcher.Respond(1, func(evt *Event) (interface{}, error) {
+		return "worker-a", nil
+	})
+	dispatcher.Respond(1, func(evt *Event) (interface{}, error) {
+		return "worker-b", nil
+	})
+
+	results, err := dispatcher.Request(context.Background(), NewEvent(1, nil, "job"), REQUEST_ALL)
+	if err != nil || len(results) != 2 || results[0] != "worker-a" || results[1] != "worker-b" {
+		t.Fatalf("ERR:all non-nil results should be collected, got %v %v", results, err)
+	}
+
+	results, err = dispatcher.Request(context.Background(), NewEvent(1, nil, "job"), REQUEST_FIRST)
+	if err != nil || len(results) != 1 || results[0] != "worker-a" {
+		t.Fatalf("ERR:first non-nil result should be returned, got %v %v", results, err)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	release := make(chan struct{})
+	dispatcher.Respond(1, func(evt *Event) (interface{}, error) {
+		return "fast", nil
+	})
+	dispatcher.Respond(1, func(evt *Event) (interface{}, error) {
+		<-release
+		return "slow", nil
+	})
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	evt := NewEvent(1, nil, nil)
+	results, err := dispatcher.Request(ctx, evt, REQUEST_ALL)
+	if err != context.DeadlineExceeded {
+		t.Error("ERR:request should time out,", err)
+	}
+
+	// 后台仍在派发, 调用者的事件不应被修改
+	if evt.Phase != PHASE_NONE || evt.CurrentTarget != nil || evt.IsPropagationStopped() {
+		t.Error("ERR:request should dispatch a copy of the event")
+	}
+
+	if len(results) != 1 || results[0] != "fast" {
+		t.Errorf("ERR:results before deadline should be returned, got %v", results)
+	}
+}
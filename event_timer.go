@@ -0,0 +1,143 @@
+package goapl
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/sambios/goapl/estimer"
+)
+
+var ErrInvalidDelay = errors.New("goapl: invalid schedule delay")
+
+// 延时或定时派发的句柄
+type ScheduledEvent struct {
+	tid        uint64
+	queue      *estimer.HeapTimerQueue
+	dispatcher *EventDispatcher
+	cancelled  atomic.Bool
+	done       bool // 已不需要在Close时取消, 由dispatcher.lock保护
+}
+
+// 取消派发, 已经派发的不受影响
+func (this *ScheduledEvent) Cancel() {
+	if this.cancelled.CompareAndSwap(false, true) {
+		this.queue.DeleteTimer(this.tid)
+		this.dispatcher.forget(this)
+	}
+}
+
+// 是否已取消
+func (this *ScheduledEvent) IsCancelled() bool {
+	return this.cancelled.Load()
+}
+
+// 设置延时派发使用的定时器队列, 未设置时派发器会自己创建一个, 并在Close时停止.
+// 使用外部队列时Close只取消本派发器创建的定时器, 不会停止队列
+func (this *EventDispatcher) SetTimerQueue(queue *estimer.HeapTimerQueue) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.timers = queue
+	this.ownTimers = false
+}
+
+// 延时delay后派发一次事件, delay不能小于0
+func (this *EventDispatcher) TriggerAfter(delay time.Duration, eventType uint32, data interface{}) (*ScheduledEvent, error) {
+	return this.schedule(delay, false, eventType, data)
+}
+
+// 每隔interval派发一次事件, 直到取消. interval不能小于estimer.MIN_TIMER_INTERVAL
+func (this *EventDispatcher) TriggerEvery(interval time.Duration, eventType uint32, data interface{}) (*ScheduledEvent, error) {
+	return this.schedule(interval, true, eventType, data)
+}
+
+func (this *EventDispatcher) schedule(delay time.Duration, repeat bool, eventType uint32, data interface{}) (*ScheduledEvent, error) {
+	// 过小的间隔会让定时器在一次Tick中不停地重复触发, 饿死队列上的其他定时器
+	if delay < 0 || (repeat && delay < estimer.MIN_TIMER_INTERVAL) {
+		return nil, ErrInvalidDelay
+	}
+
+	queue, err := this.timerQueue()
+	if err != nil {
+		return nil, err
+	}
+
+	// 定时器回调中不能阻塞, 否则同一队列上的其他定时器都会停滞. 开启异步模式时交给worker派发,
+	// 队列满时即使是OVERFLOW_BLOCK也直接丢弃并计入Dropped
+	s := &ScheduledEvent{queue: queue, dispatcher: this}
+	tid, err := queue.NewTimer(delay, repeat, func() {
+		if !repeat {
+			this.forget(s)
+		}
+		if this.isClosed() {
+			return
+		}
+
+		evt := NewEvent(eventType, this, data)
+		if q := this.asyncQueue(); q != nil {
+			q.put(evt, false)
+			return
+		}
+		this.DispatchEvent(evt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 延时很短时回调可能已经执行过
+	s.tid = tid
+	this.lock.Lock()
+	if !s.done {
+		this.scheduled[s] = struct{}{}
+	}
+	this.lock.Unlock()
+	return s, nil
+}
+
+func (this *EventDispatcher) forget(s *ScheduledEvent) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	s.done = true
+	delete(this.scheduled, s)
+}
+
+func (this *EventDispatcher) isClosed() bool {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.closed
+}
+
+func (this *EventDispatcher) timerQueue() (*estimer.HeapTimerQueue, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if this.closed {
+		return nil, ErrDispatcherClosed
+	}
+
+	if this.timers == nil {
+		this.timers = estimer.NewHeapTimerQueue()
+		this.ownTimers = true
+	}
+	return this.timers, nil
+}
+
+// 取消本派发器创建的定时器, 并停止派发器自己创建的定时器队列
+func (this *EventDispatcher) stopTimers() {
+	this.lock.Lock()
+	queue := this.timers
+	own := this.ownTimers
+	this.timers = nil
+	scheduled := this.scheduled
+	this.scheduled = make(map[*ScheduledEvent]struct{})
+	this.lock.Unlock()
+
+	for s := range scheduled {
+		s.Cancel()
+	}
+
+	if queue != nil && own {
+		queue.StopTimerQueue()
+	}
+}
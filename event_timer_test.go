@@ -0,0 +1,145 @@
+package goapl
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sambios/goapl/estimer"
+)
+
+func TestTriggerAfter(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	defer dispatcher.Close()
+
+	var x int32
+	dispatcher.On(1, func(evt *Event) error {
+		atomic.AddInt32(&x, int32(evt.Data.(int)))
+		return nil
+	})
+
+	if _, err := dispatcher.TriggerAfter(20*time.Millisecond, 1, 1); err != nil {
+		t.Fatal("ERR:TriggerAfter failed,", err)
+	}
+
+	cancelled, _ := dispatcher.TriggerAfter(20*time.Millisecond, 1, 10)
+	cancelled.Cancel()
+
+	time.Sleep(100 * time.Millisecond)
+	if n := atomic.LoadInt32(&x); n != 1 {
+		t.Errorf("ERR:only the uncancelled event should fire, got %d", n)
+	}
+}
+
+func TestTriggerEvery(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	var x int32
+	dispatcher.AddEventListener(1, func() {
+		atomic.AddInt32(&x, 1)
+	})
+
+	scheduled, err := dispatcher.TriggerEvery(20*time.Millisecond, 1, nil)
+	if err != nil {
+		t.Fatal("ERR:TriggerEvery failed,", err)
+	}
+
+	time.Sleep(110 * time.Millisecond)
+	scheduled.Cancel()
+	n := atomic.LoadInt32(&x)
+	if n < 3 {
+		t.Errorf("ERR:repeating event should fire several times, got %d", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if atomic.LoadInt32(&x) != n {
+		t.Error("ERR:cancelled event should stop firing")
+	}
+
+	dispatcher.Close()
+	if _, err := dispatcher.TriggerAfter(time.Millisecond, 1, nil); err != ErrDispatcherClosed {
+		t.Error("ERR:closed dispatcher should reject scheduling")
+	}
+}
+
+func TestTriggerInvalidDelay(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	defer dispatcher.Close()
+
+	if _, err := dispatcher.TriggerAfter(-time.Millisecond, 1, nil); err != ErrInvalidDelay {
+		t.Error("ERR:negative delay should be rejected")
+	}
+	if _, err := dispatcher.TriggerEvery(0, 1, nil); err != ErrInvalidDelay {
+		t.Error("ERR:zero interval should be rejected")
+	}
+
+	if s, err := dispatcher.TriggerAfter(0, 1, nil); err != nil {
+		t.Error("ERR:zero delay should fire immediately,", err)
+	} else {
+		s.Cancel()
+	}
+}
+
+func TestScheduleFullQueueDoesNotBlockTimers(t *testing.T) {
+	timers := estimer.NewHeapTimerQueue()
+	defer timers.StopTimerQueue()
+
+	dispatcher := NewEventDispatcher()
+	dispatcher.SetTimerQueue(timers)
+
+	release := make(chan struct{})
+	dispatcher.AddEventListener(1, func() {
+		<-release
+	})
+	dispatcher.StartAsync(AsyncOptions{Workers: 1, QueueSize: 1, Overflow: OVERFLOW_BLOCK})
+
+	scheduled, _ := dispatcher.TriggerEvery(time.Millisecond, 1, nil)
+
+	// 异步队列满时其他定时器仍然要按时触发
+	fired := make(chan struct{})
+	timers.NewTimer(20*time.Millisecond, false, func() {
+		close(fired)
+	})
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("ERR:full async queue should not block the timer queue")
+	}
+
+	scheduled.Cancel()
+	close(release)
+	dispatcher.Close()
+
+	if e := dispatcher.Stats().Events[0]; e.Dropped == 0 {
+		t.Errorf("ERR:events that do not fit should be dropped, %+v", e)
+	}
+}
+
+func TestCloseCancelsExternalTimers(t *testing.T) {
+	timers := estimer.NewHeapTimerQueue()
+	defer timers.StopTimerQueue()
+
+	dispatcher := NewEventDispatcher()
+	dispatcher.SetTimerQueue(timers)
+
+	var x int32
+	dispatcher.AddEventListener(1, func() {
+		atomic.AddInt32(&x, 1)
+	})
+	dispatcher.TriggerEvery(time.Millisecond, 1, nil)
+	dispatcher.TriggerAfter(time.Hour, 1, nil)
+
+	time.Sleep(10 * time.Millisecond)
+	dispatcher.Close()
+	time.Sleep(5 * time.Millisecond) // 等待可能正在执行的回调
+	n := atomic.LoadInt32(&x)
+
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt32(&x) != n {
+		t.Error("ERR:timers on an external queue should stop after Close")
+	}
+
+	if len(dispatcher.scheduled) != 0 {
+		t.Error("ERR:closed dispatcher should not keep scheduled events")
+	}
+}
@@ -0,0 +1,99 @@
+package goapl
+
+import "strings"
+
+// 事件调度器添加按主题监听的事件, 主题以"."分隔层级, 如"timer.fired".
+// pattern中"*"匹配一个层级, "**"匹配零个或多个层级, 如"timer.*", "timer.**", 规则见MatchTopic.
+// 主题匹配的粘性事件会立即按派发顺序派发给新监听
+func (this *EventDispatcher) OnTopic(pattern string, fn EventListenerFunc, opts ...ListenerOption) *EventListener {
+	listener := this.newListener(fn, opts)
+	listener.topic = pattern
+
+	this.lock.Lock()
+	this.idbase++
+	listener.id = this.idbase
+	this.topics.add(listener)
+	sticky := this.stickyEvents(func(evt *Event) bool {
+		return evt.Topic != "" && MatchTopic(pattern, evt.Topic)
+	})
+	this.lock.Unlock()
+
+	for _, last := range sticky {
+		this.deliverSticky(listener, last)
+	}
+	return listener
+}
+
+// 事件调度器派发主题事件
+func (this *EventDispatcher) DispatchTopic(topic string, data interface{}) error {
+	evt := NewEvent(0, this, data)
+	evt.Topic = topic
+	return this.DispatchEvent(evt)
+}
+
+// 绑定主题和数值事件类型, 之后两者派发时会同时通知主题监听和数值监听.
+// 未绑定的主题事件只通知主题监听和OnAll监听
+func (this *EventDispatcher) BindTopic(topic string, eventType uint32) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if old, ok := this.topicTypes[topic]; ok {
+		delete(this.typeTopics, old)
+	}
+	if old, ok := this.typeTopics[eventType]; ok {
+		delete(this.topicTypes, old)
+	}
+
+	this.topicTypes[topic] = eventType
+	this.typeTopics[eventType] = topic
+}
+
+// 是否有监听会收到该主题的事件
+func (this *EventDispatcher) HasTopicListener(topic string) bool {
+	evt := NewEvent(0, this, nil)
+	evt.Topic = topic
+	this.resolve(evt)
+	return len(this.snapshot(evt, PHASE_AT_TARGET)) > 0
+}
+
+// 主题监听的匹配模式
+func (this *EventListener) Topic() string {
+	return this.topic
+}
+
+// 找出匹配主题的监听, 保持原有顺序
+func (this *EventSaver) match(topic string) []*EventListener {
+	var listeners []*EventListener
+	for _, listener := range this.Listeners {
+		if MatchTopic(listener.topic, topic) {
+			listeners = append(listeners, listener)
+		}
+	}
+	return listeners
+}
+
+// 判断主题是否匹配模式. "*"匹配一个层级, "**"在任意位置匹配零个或多个层级,
+// 因此"timer.**"也匹配"timer"本身, "a.**.b"匹配"a.b"和"a.x.y.b"
+func MatchTopic(pattern string, topic string) bool {
+	return matchSegments(strings.Split(pattern, "."), strings.Split(topic, "."))
+}
+
+func matchSegments(patterns, topics []string) bool {
+	for len(patterns) > 0 {
+		p := patterns[0]
+		if p == "**" {
+			for i := 0; i <= len(topics); i++ {
+				if matchSegments(patterns[1:], topics[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(topics) == 0 || (p != "*" && p != topics[0]) {
+			return false
+		}
+		patterns, topics = patterns[1:], topics[1:]
+	}
+	return len(topics) == 0
+}
@@ -0,0 +1,108 @@
+package goapl
+
+import "testing"
+
+func TestMatchTopic(t *testing.T) {
+	cases := []struct {
+		pattern, topic string
+		match          bool
+	}{
+		{"timer.fired", "timer.fired", true},
+		{"timer.*", "timer.fired", true},
+		{"timer.*", "timer.fired.late", false},
+		{"timer.**", "timer.fired.late", true},
+		{"timer.**", "timer", true},
+		{"*.fired", "timer.fired", true},
+		{"timer", "timer.fired", false},
+		{"log.*", "timer.fired", false},
+		{"a.**.b", "a.x", false},
+		{"a.**.b", "a.b", true},
+		{"a.**.b", "a.x.y.b", true},
+		{"a.**.b", "a.x.b.c", false},
+		{"**", "timer.fired", true},
+	}
+
+	for _, c := range cases {
+		if MatchTopic(c.pattern, c.topic) != c.match {
+			t.Errorf("ERR:MatchTopic(%q, %q) should be %v", c.pattern, c.topic, c.match)
+		}
+	}
+}
+
+func TestDispatchTopic(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	var got []string
+	dispatcher.OnTopic(HELLO_WORLD, func(evt *Event) error {
+		got = append(got, "exact:"+evt.Data.(string))
+		return nil
+	})
+	dispatcher.OnTopic("timer.*", func(evt *Event) error {
+		got = append(got, "glob:"+evt.Topic)
+		return nil
+	})
+
+	dispatcher.DispatchTopic(HELLO_WORLD, "hi")
+	dispatcher.DispatchTopic("timer.fired", nil)
+	dispatcher.DispatchTopic("log.write", nil)
+
+	if len(got) != 2 || got[0] != "exact:hi" || got[1] != "glob:timer.fired" {
+		t.Errorf("ERR:topic listeners got %v", got)
+	}
+}
+
+func TestBindTopic(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	dispatcher.BindTopic("timer.fired", 7)
+
+	numeric, topic := 0, 0
+	dispatcher.AddEventListener(7, func() {
+		numeric++
+	})
+	dispatcher.OnTopic("timer.*", func(evt *Event) error {
+		if evt.Type == 7 {
+			topic++
+		}
+		return nil
+	})
+
+	dispatcher.EventTrigger(7)
+	dispatcher.DispatchTopic("timer.fired", nil)
+
+	if numeric != 2 || topic != 2 {
+		t.Errorf("ERR:bound topic should reach both listeners, numeric=%d topic=%d", numeric, topic)
+	}
+
+	// 未绑定的主题不会派发给数值类型为0的监听
+	zero := 0
+	dispatcher.AddEventListener(0, func() {
+		zero++
+	})
+	dispatcher.DispatchTopic("timer.stopped", nil)
+	if zero != 0 {
+		t.Error("ERR:unbound topic should not reach numeric listeners")
+	}
+}
+
+func TestTopicKeepsEventType(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	numeric, topic := 0, 0
+	dispatcher.On(5, func(evt *Event) error {
+		numeric++
+		return nil
+	})
+	dispatcher.OnTopic("user.login", func(evt *Event) error {
+		topic++
+		return nil
+	})
+
+	// 主题未绑定时也保留调用者指定的Type
+	evt := NewEvent(5, nil, nil)
+	evt.Topic = "user.login"
+	dispatcher.DispatchEvent(evt)
+
+	if evt.Type != 5 || numeric != 1 || topic != 1 {
+		t.Errorf("ERR:type=%d numeric=%d topic=%d", evt.Type, numeric, topic)
+	}
+}
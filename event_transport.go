@@ -0,0 +1,475 @@
+package goapl
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sambios/goapl/eslog"
+)
+
+// 单个事件编码后的最大长度
+const MAX_EVENT_FRAME = 16 * 1024 * 1024
+
+var ErrTransportClosed = errors.New("goapl: transport closed")
+
+// 事件编解码接口, 用于跨进程传输事件
+type EventCodec interface {
+	Encode(evt *Event) ([]byte, error)
+	Decode(data []byte) (*Event, error)
+}
+
+// 事件传输接口, Send把事件发给对端, 对端收到的事件交给receiver
+type EventTransport interface {
+	Send(evt *Event) error
+	SetReceiver(receiver func(evt *Event))
+	Close() error
+}
+
+// 转发事件失败
+type TransportError struct {
+	Transport EventTransport
+	Err       error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("forward event: %v", e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+//
+// JSON codec
+//
+
+type jsonEvent struct {
+	Type    uint32          `json:"type"`
+	Topic   string          `json:"topic,omitempty"`
+	Time    time.Time       `json:"time"`
+	TraceId string          `json:"trace_id,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// 以JSON编码事件, Data按注册的类型解码, 未注册时解码为map等通用类型.
+// Source和context不会传输, 只有eslog的trace id会传给对端
+type JSONCodec struct {
+	payloads map[statsKey]func() interface{}
+	lock     sync.RWMutex
+}
+
+func NewJSONCodec() *JSONCodec {
+	return &JSONCodec{payloads: make(map[statsKey]func() interface{})}
+}
+
+// 注册数值事件的Data类型, newPayload返回用于解码的指针, 如func() interface{} { return &Job{} }
+func (c *JSONCodec) RegisterPayload(eventType uint32, newPayload func() interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.payloads[statsKey{eventType: eventType}] = newPayload
+}
+
+// 注册主题事件的Data类型
+func (c *JSONCodec) RegisterTopicPayload(topic string, newPayload func() interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.payloads[statsKey{topic: topic}] = newPayload
+}
+
+func (c *JSONCodec) Encode(evt *Event) ([]byte, error) {
+	msg := jsonEvent{
+		Type:    evt.Type,
+		Topic:   evt.Topic,
+		Time:    evt.Time,
+		TraceId: eslog.TraceIDFromContext(evt.ctx),
+	}
+
+	if evt.Data != nil {
+		data, err := json.Marshal(evt.Data)
+		if err != nil {
+			return nil, err
+		}
+		msg.Data = data
+	}
+	return json.Marshal(&msg)
+}
+
+func (c *JSONCodec) Decode(data []byte) (*Event, error) {
+	var msg jsonEvent
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+
+	evt := NewEvent(msg.Type, nil, nil)
+	evt.Topic = msg.Topic
+	evt.Time = msg.Time
+	if msg.TraceId != "" {
+		evt.SetContext(eslog.WithTraceID(context.Background(), msg.TraceId))
+	}
+
+	if len(msg.Data) == 0 {
+		return evt, nil
+	}
+
+	c.lock.RLock()
+	newPayload, ok := c.payloads[statsKey{eventType: msg.Type}]
+	if msg.Topic != "" {
+		if byTopic, found := c.payloads[statsKey{topic: msg.Topic}]; found {
+			newPayload, ok = byTopic, true
+		}
+	}
+	c.lock.RUnlock()
+
+	if ok {
+		evt.Data = newPayload()
+		return evt, json.Unmarshal(msg.Data, evt.Data)
+	}
+	return evt, json.Unmarshal(msg.Data, &evt.Data)
+}
+
+//
+// Loopback transport
+//
+
+// 进程内的传输, 成对创建, 一端发送的事件经过编解码后同步交给另一端, 用于测试
+type LoopbackTransport struct {
+	codec    EventCodec
+	peer     *LoopbackTransport
+	receiver func(evt *Event)
+	closed   bool
+	lock     sync.RWMutex
+}
+
+// 创建一对互相连接的进程内传输, codec为nil时使用JSONCodec
+func NewLoopbackTransport(codec EventCodec) (*LoopbackTransport, *LoopbackTransport) {
+	if codec == nil {
+		codec = NewJSONCodec()
+	}
+
+	a := &LoopbackTransport{codec: codec}
+	b := &LoopbackTransport{codec: codec, peer: a}
+	a.peer = b
+	return a, b
+}
+
+func (t *LoopbackTransport) Send(evt *Event) error {
+	t.lock.RLock()
+	closed := t.closed
+	t.lock.RUnlock()
+	if closed {
+		return ErrTransportClosed
+	}
+
+	data, err := t.codec.Encode(evt)
+	if err != nil {
+		return err
+	}
+
+	remote, err := t.codec.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	t.peer.lock.RLock()
+	receiver := t.peer.receiver
+	closed = t.peer.closed
+	t.peer.lock.RUnlock()
+
+	if closed {
+		return ErrTransportClosed
+	}
+	if receiver != nil {
+		receiver(remote)
+	}
+	return nil
+}
+
+func (t *LoopbackTransport) SetReceiver(receiver func(evt *Event)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.receiver = receiver
+}
+
+func (t *LoopbackTransport) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.closed = true
+	return nil
+}
+
+//
+// Stream transport, for TCP or unix socket
+//
+
+// 基于net.Conn的传输, 每个事件编码为4字节大端长度加内容
+type ConnTransport struct {
+	conn      net.Conn
+	codec     EventCodec
+	receiver  func(evt *Event)
+	onError   func(err error)
+	reading   bool
+	writeLock sync.Mutex
+	lock      sync.Mutex
+	done      chan struct{}
+	err       error
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// 在已建立的连接上创建传输, codec为nil时使用JSONCodec
+func NewConnTransport(conn net.Conn, codec EventCodec) *ConnTransport {
+	if codec == nil {
+		codec = NewJSONCodec()
+	}
+	return &ConnTransport{conn: conn, codec: codec, done: make(chan struct{})}
+}
+
+// 连接到对端派发器, network为"tcp"或"unix"
+func DialTransport(network, address string, codec EventCodec) (*ConnTransport, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return NewConnTransport(conn, codec), nil
+}
+
+func (t *ConnTransport) Send(evt *Event) error {
+	data, err := t.codec.Encode(evt)
+	if err != nil {
+		return err
+	}
+
+	if len(data) > MAX_EVENT_FRAME {
+		return fmt.Errorf("goapl: event frame too large: %d", len(data))
+	}
+
+	frame := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	copy(frame[4:], data)
+
+	t.writeLock.Lock()
+	defer t.writeLock.Unlock()
+	_, err = t.conn.Write(frame)
+	return err
+}
+
+// 设置接收回调, 第一次调用时开始读取连接
+func (t *ConnTransport) SetReceiver(receiver func(evt *Event)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.receiver = receiver
+	if !t.reading {
+		t.reading = true
+		go t.readLoop()
+	}
+}
+
+// 设置读取错误的回调: 无法解码的帧会被跳过并通知一次, 读取goroutine因连接
+// 出错而退出时也会通知. 未设置时输出到stderr
+func (t *ConnTransport) SetErrorHandler(handler func(err error)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.onError = handler
+}
+
+// 关闭连接并等待读取goroutine退出
+func (t *ConnTransport) Close() error {
+	err := t.closeConn()
+
+	t.lock.Lock()
+	reading := t.reading
+	t.lock.Unlock()
+
+	if reading {
+		<-t.done
+	}
+	return err
+}
+
+// 读取goroutine退出的原因, 连接正常关闭时为io.EOF或net.ErrClosed
+func (t *ConnTransport) Err() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.err
+}
+
+func (t *ConnTransport) closeConn() error {
+	t.closeOnce.Do(func() {
+		t.closeErr = t.conn.Close()
+	})
+	return t.closeErr
+}
+
+func (t *ConnTransport) report(err error) {
+	t.lock.Lock()
+	handler := t.onError
+	t.lock.Unlock()
+
+	if handler != nil {
+		handler(err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "ConnTransport(%s): %v\n", t.conn.RemoteAddr(), err)
+}
+
+func (t *ConnTransport) readLoop() {
+	defer close(t.done)
+
+	r := bufio.NewReader(t.conn)
+	header := make([]byte, 4)
+	for {
+		data, err := t.readFrame(r, header)
+		if err != nil {
+			t.lock.Lock()
+			t.err = err
+			t.lock.Unlock()
+
+			// 帧已经无法对齐, 关闭连接使对端的Send返回错误而不是一直阻塞
+			t.closeConn()
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				t.report(err)
+			}
+			return
+		}
+
+		// 长度前缀完整时单个帧解码失败不影响后续的帧
+		evt, err := t.codec.Decode(data)
+		if err != nil {
+			t.report(fmt.Errorf("goapl: decode event frame: %w", err))
+			continue
+		}
+
+		t.lock.Lock()
+		receiver := t.receiver
+		t.lock.Unlock()
+
+		receiver(evt)
+	}
+}
+
+func (t *ConnTransport) readFrame(r io.Reader, header []byte) ([]byte, error) {
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header)
+	if size > MAX_EVENT_FRAME {
+		return nil, fmt.Errorf("goapl: event frame too large: %d", size)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// 监听对端派发器的连接
+type TransportListener struct {
+	listener net.Listener
+	codec    EventCodec
+}
+
+// 在address上监听, network为"tcp"或"unix"
+func ListenTransport(network, address string, codec EventCodec) (*TransportListener, error) {
+	l, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return &TransportListener{listener: l, codec: codec}, nil
+}
+
+// 等待一个对端连接
+func (l *TransportListener) Accept() (*ConnTransport, error) {
+	conn, err := l.listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return NewConnTransport(conn, l.codec), nil
+}
+
+func (l *TransportListener) Addr() net.Addr {
+	return l.listener.Addr()
+}
+
+func (l *TransportListener) Close() error {
+	return l.listener.Close()
+}
+
+//
+// Dispatcher
+//
+
+type attachedTransport struct {
+	transport EventTransport
+	filter    func(evt *Event) bool
+}
+
+// 连接传输层: 本派发器上派发的事件(filter为nil或返回true时)在本地派发完后转发给对端,
+// 被本地监听StopPropagation或ctx已取消的事件不转发. 对端发来的事件在本地派发,
+// Source为该传输, 且不会再次转发
+func (this *EventDispatcher) AttachTransport(transport EventTransport, filter func(evt *Event) bool) {
+	this.lock.Lock()
+	transports := make([]attachedTransport, 0, len(this.transports)+1)
+	transports = append(transports, this.transports...)
+	this.transports = append(transports, attachedTransport{transport: transport, filter: filter})
+	this.lock.Unlock()
+
+	transport.SetReceiver(func(evt *Event) {
+		evt.Source = transport
+		evt.remote = true
+		this.DispatchEvent(evt)
+	})
+}
+
+// 断开传输层, 不会关闭transport
+func (this *EventDispatcher) DetachTransport(transport EventTransport) bool {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	for i, t := range this.transports {
+		if t.transport != transport {
+			continue
+		}
+
+		transports := make([]attachedTransport, 0, len(this.transports)-1)
+		transports = append(transports, this.transports[:i]...)
+		this.transports = append(transports, this.transports[i+1:]...)
+		transport.SetReceiver(func(evt *Event) {})
+		return true
+	}
+	return false
+}
+
+// 把本地事件转发到所有传输层
+func (this *EventDispatcher) forward(evt *Event) error {
+	if evt.remote {
+		return nil
+	}
+
+	this.lock.RLock()
+	transports := this.transports
+	this.lock.RUnlock()
+
+	var errs []error
+	for _, t := range transports {
+		if t.filter != nil && !t.filter(evt) {
+			continue
+		}
+
+		if err := t.transport.Send(evt); err != nil {
+			errs = append(errs, &TransportError{Transport: t.transport, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
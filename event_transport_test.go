@@ -0,0 +1,206 @@
+package goapl
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sambios/goapl/eslog"
+)
+
+type testJob struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+func TestLoopbackTransport(t *testing.T) {
+	codec := NewJSONCodec()
+	codec.RegisterPayload(1, func() interface{} {
+		return &testJob{}
+	})
+
+	a, b := NewLoopbackTransport(codec)
+	local := NewEventDispatcher()
+	peer := NewEventDispatcher()
+	local.AttachTransport(a, nil)
+	peer.AttachTransport(b, nil)
+
+	var got *testJob
+	var traceId string
+	peer.On(1, func(evt *Event) error {
+		if !evt.IsRemote() || evt.Source != b {
+			t.Error("ERR:event should come from transport")
+		}
+		got = evt.Data.(*testJob)
+		traceId = eslog.TraceIDFromContext(evt.Context())
+		return nil
+	})
+
+	// 本地也监听, 确认对端收到的事件不会再转发回来
+	echo := 0
+	local.AddEventListener(1, func() {
+		echo++
+	})
+
+	ctx := eslog.WithTraceID(context.Background(), "req-1")
+	err := local.DispatchEventContext(ctx, NewEvent(1, nil, testJob{Name: "build", Size: 3}))
+	if err != nil {
+		t.Fatal("ERR:dispatch should succeed,", err)
+	}
+
+	if got == nil || got.Name != "build" || got.Size != 3 {
+		t.Fatalf("ERR:peer should decode payload, got %+v", got)
+	}
+
+	if traceId != "req-1" {
+		t.Error("ERR:trace id should be forwarded")
+	}
+
+	if echo != 1 {
+		t.Errorf("ERR:remote event should not be forwarded back, echo=%d", echo)
+	}
+
+	local.DetachTransport(a)
+	got = nil
+	local.DispatchEvent(NewEvent(1, nil, testJob{}))
+	if got != nil {
+		t.Error("ERR:detached transport should not forward")
+	}
+}
+
+func TestConnTransport(t *testing.T) {
+	address := filepath.Join(t.TempDir(), "events.sock")
+	listener, err := ListenTransport("unix", address, nil)
+	if err != nil {
+		t.Fatal("ERR:listen failed,", err)
+	}
+	defer listener.Close()
+
+	accepted := make(chan *ConnTransport, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+
+	client, err := DialTransport("unix", address, nil)
+	if err != nil {
+		t.Fatal("ERR:dial failed,", err)
+	}
+	defer client.Close()
+
+	server := <-accepted
+	defer server.Close()
+
+	received := make(chan *Event, 1)
+	peer := NewEventDispatcher()
+	peer.AttachTransport(server, nil)
+	peer.OnTopic("job.*", func(evt *Event) error {
+		received <- evt
+		return nil
+	})
+
+	local := NewEventDispatcher()
+	local.AttachTransport(client, func(evt *Event) bool {
+		return evt.Topic != ""
+	})
+	local.EventTrigger(5)
+	local.DispatchTopic("job.done", map[string]interface{}{"id": 7.0})
+
+	select {
+	case evt := <-received:
+		if evt.Topic != "job.done" || evt.Data.(map[string]interface{})["id"] != 7.0 {
+			t.Errorf("ERR:received wrong event %+v", evt)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ERR:event not received")
+	}
+}
+
+func TestConnTransportBadFrame(t *testing.T) {
+	local, remote := net.Pipe()
+	transport := NewConnTransport(local, nil)
+	defer transport.Close()
+
+	errs := make(chan error, 4)
+	transport.SetErrorHandler(func(err error) {
+		errs <- err
+	})
+
+	received := make(chan *Event, 1)
+	transport.SetReceiver(func(evt *Event) {
+		received <- evt
+	})
+
+	// 无法解码的帧被跳过, 之后的帧仍能收到
+	frame := []byte{0, 0, 0, 3, 'b', 'a', 'd'}
+	if _, err := remote.Write(frame); err != nil {
+		t.Fatal("ERR:write failed,", err)
+	}
+	peer := NewConnTransport(remote, nil)
+	evt := NewEvent(0, nil, nil)
+	evt.Topic = "job.done"
+	if err := peer.Send(evt); err != nil {
+		t.Fatal("ERR:send after bad frame failed,", err)
+	}
+
+	select {
+	case evt := <-received:
+		if evt.Topic != "job.done" {
+			t.Errorf("ERR:received wrong event %+v", evt)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ERR:event after bad frame not received")
+	}
+	if len(errs) != 1 {
+		t.Errorf("ERR:bad frame should be reported once, got %d", len(errs))
+	}
+
+	// 超长的帧无法再对齐, 连接被关闭, 对端的写入立即失败
+	remote.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	select {
+	case <-errs:
+	case <-time.After(time.Second):
+		t.Fatal("ERR:oversized frame should be reported")
+	}
+	if err := peer.Send(evt); err == nil {
+		t.Error("ERR:send should fail after the connection is closed")
+	}
+}
+
+func TestTransportSkipsStoppedEvents(t *testing.T) {
+	a, b := NewLoopbackTransport(nil)
+	local := NewEventDispatcher()
+	peer := NewEventDispatcher()
+	local.AttachTransport(a, nil)
+	peer.AttachTransport(b, nil)
+
+	received := 0
+	peer.AddEventListener(1, func() {
+		received++
+	})
+	local.On(1, func(evt *Event) error {
+		if evt.Data == "stop" {
+			evt.StopPropagation()
+		}
+		return nil
+	})
+
+	local.DispatchEvent(NewEvent(1, nil, "stop"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	local.DispatchEventContext(ctx, NewEvent(1, nil, "cancelled"))
+
+	if received != 0 {
+		t.Errorf("ERR:stopped or cancelled events should not be forwarded, got %d", received)
+	}
+
+	local.DispatchEvent(NewEvent(1, nil, "go"))
+	if received != 1 {
+		t.Error("ERR:normal events should still be forwarded")
+	}
+}
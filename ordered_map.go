@@ -0,0 +1,172 @@
+package goapl
+
+import "iter"
+
+type orderedEntry[K comparable, V any] struct {
+	key        K
+	value      V
+	prev, next *orderedEntry[K, V]
+}
+
+// 按插入顺序遍历的map, 增删查都是O(1). 更新已有的键不改变其位置. 零值为可直接使用的空map
+type OrderedMap[K comparable, V any] struct {
+	m    map[K]*orderedEntry[K, V]
+	root orderedEntry[K, V] // 哨兵, root.next为最早插入的, root.prev为最后插入的
+}
+
+func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+	om := &OrderedMap[K, V]{}
+	om.lazyInit()
+	return om
+}
+
+// 设置键值, 返回是否为新插入的键
+func (om *OrderedMap[K, V]) Set(key K, value V) bool {
+	om.lazyInit()
+	if e, ok := om.m[key]; ok {
+		e.value = value
+		return false
+	}
+
+	e := &orderedEntry[K, V]{key: key, value: value}
+	om.insertBefore(e, &om.root)
+	om.m[key] = e
+	return true
+}
+
+func (om *OrderedMap[K, V]) Get(key K) (V, bool) {
+	if e, ok := om.m[key]; ok {
+		return e.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (om *OrderedMap[K, V]) Has(key K) bool {
+	_, ok := om.m[key]
+	return ok
+}
+
+// 删除键, 返回被删除的值
+func (om *OrderedMap[K, V]) Delete(key K) (V, bool) {
+	e, ok := om.m[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	om.unlink(e)
+	delete(om.m, key)
+	return e.value, true
+}
+
+func (om *OrderedMap[K, V]) Len() int {
+	return len(om.m)
+}
+
+func (om *OrderedMap[K, V]) Clear() {
+	om.lazyInit()
+	clear(om.m)
+	om.root.next = &om.root
+	om.root.prev = &om.root
+}
+
+// 最早插入的键值
+func (om *OrderedMap[K, V]) Front() (K, V, bool) {
+	om.lazyInit()
+	return om.entry(om.root.next)
+}
+
+// 最后插入的键值
+func (om *OrderedMap[K, V]) Back() (K, V, bool) {
+	om.lazyInit()
+	return om.entry(om.root.prev)
+}
+
+// 把键移到最前, 键不存在时返回false
+func (om *OrderedMap[K, V]) MoveToFront(key K) bool {
+	e, ok := om.m[key]
+	if !ok {
+		return false
+	}
+	om.unlink(e)
+	om.insertBefore(e, om.root.next)
+	return true
+}
+
+// 把键移到最后, 键不存在时返回false
+func (om *OrderedMap[K, V]) MoveToBack(key K) bool {
+	e, ok := om.m[key]
+	if !ok {
+		return false
+	}
+	om.unlink(e)
+	om.insertBefore(e, &om.root)
+	return true
+}
+
+// 按顺序返回所有键
+func (om *OrderedMap[K, V]) Keys() []K {
+	om.lazyInit()
+	keys := make([]K, 0, len(om.m))
+	for e := om.root.next; e != &om.root; e = e.next {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+// 按顺序返回所有值
+func (om *OrderedMap[K, V]) Values() []V {
+	om.lazyInit()
+	values := make([]V, 0, len(om.m))
+	for e := om.root.next; e != &om.root; e = e.next {
+		values = append(values, e.value)
+	}
+	return values
+}
+
+// 按顺序遍历, 遍历中可以删除当前的键
+func (om *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	om.lazyInit()
+	return func(yield func(K, V) bool) {
+		for e := om.root.next; e != &om.root; {
+			next := e.next
+			if !yield(e.key, e.value) {
+				return
+			}
+			e = next
+		}
+	}
+}
+
+// 零值第一次使用时初始化
+func (om *OrderedMap[K, V]) lazyInit() {
+	if om.root.next == nil {
+		om.m = make(map[K]*orderedEntry[K, V])
+		om.root.next = &om.root
+		om.root.prev = &om.root
+	}
+}
+
+func (om *OrderedMap[K, V]) entry(e *orderedEntry[K, V]) (K, V, bool) {
+	if e == &om.root {
+		var key K
+		var value V
+		return key, value, false
+	}
+	return e.key, e.value, true
+}
+
+func (om *OrderedMap[K, V]) insertBefore(e, mark *orderedEntry[K, V]) {
+	e.prev = mark.prev
+	e.next = mark
+	mark.prev.next = e
+	mark.prev = e
+}
+
+func (om *OrderedMap[K, V]) unlink(e *orderedEntry[K, V]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev = nil
+	e.next = nil
+}
@@ -0,0 +1,66 @@
+package goapl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOrderedMap(t *testing.T) {
+	om := NewOrderedMap[string, int]()
+	for i, k := range []string{"c", "a", "d", "b"} {
+		om.Set(k, i)
+	}
+
+	if om.Set("a", 10) {
+		t.Fatal("ERR:updating a should not insert")
+	}
+
+	if got := strings.Join(om.Keys(), ""); got != "cadb" {
+		t.Fatalf("ERR:keys should keep insertion order, got %s", got)
+	}
+
+	if v, ok := om.Get("a"); !ok || v != 10 {
+		t.Fatal("ERR:a should be 10")
+	}
+
+	if _, ok := om.Delete("d"); !ok || om.Has("d") || om.Len() != 3 {
+		t.Fatal("ERR:d should be deleted")
+	}
+
+	om.MoveToBack("c")
+	om.MoveToFront("b")
+	if got := strings.Join(om.Keys(), ""); got != "bac" {
+		t.Fatalf("ERR:keys should be bac, got %s", got)
+	}
+
+	if k, v, ok := om.Front(); !ok || k != "b" || v != 3 {
+		t.Error("ERR:front should be b")
+	}
+
+	// 遍历中删除当前键
+	var visited []string
+	for k := range om.All() {
+		visited = append(visited, k)
+		om.Delete(k)
+	}
+	if strings.Join(visited, "") != "bac" || om.Len() != 0 {
+		t.Error("ERR:iteration with delete failed")
+	}
+
+	if _, _, ok := om.Back(); ok {
+		t.Error("ERR:empty map should have no back")
+	}
+}
+
+func TestOrderedMapZeroValue(t *testing.T) {
+	var om OrderedMap[string, int]
+	if _, _, ok := om.Front(); ok || len(om.Keys()) != 0 {
+		t.Fatal("ERR:zero value map should be empty")
+	}
+
+	om.Set("a", 1)
+	om.Set("b", 2)
+	if k, v, ok := om.Back(); !ok || k != "b" || v != 2 || om.Len() != 2 {
+		t.Error("ERR:zero value map should be usable")
+	}
+}
@@ -0,0 +1,179 @@
+package goapl
+
+import "container/heap"
+
+// 二叉堆实现的优先队列, less(a, b)为true时a先出队. 需由NewPriorityQueue创建
+type PriorityQueue[T any] struct {
+	h valueHeap[T]
+}
+
+func NewPriorityQueue[T any](less func(a, b T) bool) *PriorityQueue[T] {
+	return &PriorityQueue[T]{h: valueHeap[T]{less: less}}
+}
+
+func (q *PriorityQueue[T]) Push(n T) {
+	heap.Push(&q.h, n)
+}
+
+// 取出优先级最高的元素
+func (q *PriorityQueue[T]) Pop() (T, error) {
+	if q.h.Len() == 0 {
+		var zero T
+		return zero, ErrEmpty
+	}
+	return heap.Pop(&q.h).(T), nil
+}
+
+// 查看优先级最高的元素但不取出
+func (q *PriorityQueue[T]) Peek() (T, error) {
+	if q.h.Len() == 0 {
+		var zero T
+		return zero, ErrEmpty
+	}
+	return q.h.items[0], nil
+}
+
+func (q *PriorityQueue[T]) Len() int {
+	return q.h.Len()
+}
+
+// 索引优先队列的元素句柄, 用于修改或删除队列中的元素
+type PQHandle[T any] struct {
+	value T
+	index int // 在堆中的位置, 不在队列中时为-1
+}
+
+func (h *PQHandle[T]) Value() T {
+	return h.value
+}
+
+// 支持按句柄修改和删除元素的优先队列, Update和Remove都是O(log n). 需由NewIndexedPriorityQueue创建
+type IndexedPriorityQueue[T any] struct {
+	h pqHeap[T]
+}
+
+func NewIndexedPriorityQueue[T any](less func(a, b T) bool) *IndexedPriorityQueue[T] {
+	return &IndexedPriorityQueue[T]{h: pqHeap[T]{less: less}}
+}
+
+// 放入元素, 返回的句柄在元素出队或删除前有效
+func (q *IndexedPriorityQueue[T]) Push(n T) *PQHandle[T] {
+	h := &PQHandle[T]{value: n}
+	heap.Push(&q.h, h)
+	return h
+}
+
+func (q *IndexedPriorityQueue[T]) Pop() (T, error) {
+	if q.h.Len() == 0 {
+		var zero T
+		return zero, ErrEmpty
+	}
+	return heap.Pop(&q.h).(*PQHandle[T]).value, nil
+}
+
+func (q *IndexedPriorityQueue[T]) Peek() (T, error) {
+	if q.h.Len() == 0 {
+		var zero T
+		return zero, ErrEmpty
+	}
+	return q.h.items[0].value, nil
+}
+
+// 修改元素的值并调整位置, 句柄已失效时返回false
+func (q *IndexedPriorityQueue[T]) Update(h *PQHandle[T], n T) bool {
+	if !q.Contains(h) {
+		return false
+	}
+	h.value = n
+	heap.Fix(&q.h, h.index)
+	return true
+}
+
+// 删除元素, 句柄已失效时返回false
+func (q *IndexedPriorityQueue[T]) Remove(h *PQHandle[T]) (T, bool) {
+	if !q.Contains(h) {
+		var zero T
+		return zero, false
+	}
+	heap.Remove(&q.h, h.index)
+	return h.value, true
+}
+
+// 句柄对应的元素是否还在队列中
+func (q *IndexedPriorityQueue[T]) Contains(h *PQHandle[T]) bool {
+	return h != nil && h.index >= 0 && h.index < len(q.h.items) && q.h.items[h.index] == h
+}
+
+func (q *IndexedPriorityQueue[T]) Len() int {
+	return q.h.Len()
+}
+
+//
+// Heap object
+//
+
+// 存放句柄的堆, 维护每个句柄在堆中的位置
+type pqHeap[T any] struct {
+	items []*PQHandle[T]
+	less  func(a, b T) bool
+}
+
+func (h *pqHeap[T]) Len() int {
+	return len(h.items)
+}
+
+func (h *pqHeap[T]) Less(i, j int) bool {
+	return h.less(h.items[i].value, h.items[j].value)
+}
+
+func (h *pqHeap[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+
+func (h *pqHeap[T]) Push(x interface{}) {
+	item := x.(*PQHandle[T])
+	item.index = len(h.items)
+	h.items = append(h.items, item)
+}
+
+func (h *pqHeap[T]) Pop() interface{} {
+	l := len(h.items)
+	item := h.items[l-1]
+	h.items[l-1] = nil
+	h.items = h.items[:l-1]
+	item.index = -1
+	return item
+}
+
+// 直接存放元素的堆, 用于不需要句柄的PriorityQueue
+type valueHeap[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+func (h *valueHeap[T]) Len() int {
+	return len(h.items)
+}
+
+func (h *valueHeap[T]) Less(i, j int) bool {
+	return h.less(h.items[i], h.items[j])
+}
+
+func (h *valueHeap[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+}
+
+func (h *valueHeap[T]) Push(x interface{}) {
+	h.items = append(h.items, x.(T))
+}
+
+func (h *valueHeap[T]) Pop() interface{} {
+	l := len(h.items)
+	item := h.items[l-1]
+	var zero T
+	h.items[l-1] = zero
+	h.items = h.items[:l-1]
+	return item
+}
@@ -0,0 +1,65 @@
+package goapl
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	q := NewPriorityQueue(func(a, b int) bool {
+		return a < b
+	})
+
+	if _, err := q.Pop(); err != ErrEmpty {
+		t.Fatal("ERR:empty queue should return ErrEmpty")
+	}
+
+	for _, n := range rand.Perm(100) {
+		q.Push(n)
+	}
+
+	if n, _ := q.Peek(); n != 0 {
+		t.Fatal("ERR:peek should return 0")
+	}
+
+	for i := 0; i < 100; i++ {
+		if n, _ := q.Pop(); n != i {
+			t.Fatalf("ERR:pop should return %d, got %d", i, n)
+		}
+	}
+}
+
+type testTask struct {
+	name     string
+	priority int
+}
+
+func TestIndexedPriorityQueue(t *testing.T) {
+	q := NewIndexedPriorityQueue(func(a, b testTask) bool {
+		return a.priority > b.priority
+	})
+
+	a := q.Push(testTask{"a", 1})
+	b := q.Push(testTask{"b", 2})
+	c := q.Push(testTask{"c", 3})
+
+	if !q.Update(a, testTask{"a", 10}) {
+		t.Fatal("ERR:update should succeed")
+	}
+
+	if n, ok := q.Remove(c); !ok || n.name != "c" {
+		t.Fatal("ERR:remove should return c")
+	}
+
+	if _, ok := q.Remove(c); ok {
+		t.Error("ERR:removed handle should be invalid")
+	}
+
+	if n, _ := q.Pop(); n.name != "a" {
+		t.Errorf("ERR:updated a should pop first, got %s", n.name)
+	}
+
+	if q.Update(a, testTask{"a", 0}) || !q.Contains(b) || q.Len() != 1 {
+		t.Error("ERR:popped handle should be invalid")
+	}
+}
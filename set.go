@@ -0,0 +1,136 @@
+package goapl
+
+import "iter"
+
+// 集合, 零值为可直接使用的空集合
+type Set[T comparable] struct {
+	m map[T]struct{}
+}
+
+func NewSet[T comparable](items ...T) *Set[T] {
+	s := &Set[T]{m: make(map[T]struct{}, len(items))}
+	for _, n := range items {
+		s.m[n] = struct{}{}
+	}
+	return s
+}
+
+// 添加元素, 已存在时返回false
+func (s *Set[T]) Add(n T) bool {
+	if _, ok := s.m[n]; ok {
+		return false
+	}
+	if s.m == nil {
+		s.m = make(map[T]struct{})
+	}
+	s.m[n] = struct{}{}
+	return true
+}
+
+// 删除元素, 不存在时返回false
+func (s *Set[T]) Remove(n T) bool {
+	if _, ok := s.m[n]; !ok {
+		return false
+	}
+	delete(s.m, n)
+	return true
+}
+
+func (s *Set[T]) Contains(n T) bool {
+	_, ok := s.m[n]
+	return ok
+}
+
+func (s *Set[T]) Len() int {
+	return len(s.m)
+}
+
+func (s *Set[T]) Clear() {
+	clear(s.m)
+}
+
+// 所有元素, 顺序不确定
+func (s *Set[T]) Items() []T {
+	items := make([]T, 0, len(s.m))
+	for n := range s.m {
+		items = append(items, n)
+	}
+	return items
+}
+
+// 遍历所有元素, 顺序不确定
+func (s *Set[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for n := range s.m {
+			if !yield(n) {
+				return
+			}
+		}
+	}
+}
+
+func (s *Set[T]) Clone() *Set[T] {
+	out := &Set[T]{m: make(map[T]struct{}, len(s.m))}
+	for n := range s.m {
+		out.m[n] = struct{}{}
+	}
+	return out
+}
+
+// 并集
+func (s *Set[T]) Union(other *Set[T]) *Set[T] {
+	out := s.Clone()
+	for n := range other.m {
+		out.m[n] = struct{}{}
+	}
+	return out
+}
+
+// 交集
+func (s *Set[T]) Intersection(other *Set[T]) *Set[T] {
+	small, large := s, other
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
+
+	out := NewSet[T]()
+	for n := range small.m {
+		if large.Contains(n) {
+			out.m[n] = struct{}{}
+		}
+	}
+	return out
+}
+
+// 差集, 在s中但不在other中的元素
+func (s *Set[T]) Difference(other *Set[T]) *Set[T] {
+	out := NewSet[T]()
+	for n := range s.m {
+		if !other.Contains(n) {
+			out.m[n] = struct{}{}
+		}
+	}
+	return out
+}
+
+// s的元素是否都在other中
+func (s *Set[T]) IsSubset(other *Set[T]) bool {
+	if s.Len() > other.Len() {
+		return false
+	}
+	for n := range s.m {
+		if !other.Contains(n) {
+			return false
+		}
+	}
+	return true
+}
+
+// other的元素是否都在s中
+func (s *Set[T]) IsSuperset(other *Set[T]) bool {
+	return other.IsSubset(s)
+}
+
+func (s *Set[T]) Equal(other *Set[T]) bool {
+	return s.Len() == other.Len() && s.IsSubset(other)
+}
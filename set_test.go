@@ -0,0 +1,43 @@
+package goapl
+
+import "testing"
+
+func TestSet(t *testing.T) {
+	a := NewSet(1, 2, 3)
+	b := NewSet(3, 4)
+
+	if a.Add(1) || !a.Add(5) || !a.Remove(5) || a.Remove(5) {
+		t.Fatal("ERR:add/remove should report changes")
+	}
+
+	if !a.Union(b).Equal(NewSet(1, 2, 3, 4)) {
+		t.Error("ERR:union wrong")
+	}
+
+	if !a.Intersection(b).Equal(NewSet(3)) {
+		t.Error("ERR:intersection wrong")
+	}
+
+	if !a.Difference(b).Equal(NewSet(1, 2)) {
+		t.Error("ERR:difference wrong")
+	}
+
+	if !NewSet(1, 2).IsSubset(a) || a.IsSubset(b) || !a.IsSuperset(NewSet(2)) {
+		t.Error("ERR:subset wrong")
+	}
+
+	n := 0
+	for range a.All() {
+		n++
+	}
+	if n != 3 || len(a.Items()) != 3 {
+		t.Error("ERR:iteration should visit 3 items")
+	}
+}
+
+func TestSetZeroValue(t *testing.T) {
+	var s Set[int]
+	if s.Contains(1) || s.Remove(1) || !s.Add(1) || s.Len() != 1 {
+		t.Error("ERR:zero value set should be usable")
+	}
+}
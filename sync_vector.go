@@ -0,0 +1,130 @@
+package goapl
+
+import (
+	"iter"
+	"sync"
+)
+
+// 并发安全的Vector, 读操作共享读锁, 写操作独占. 需由NewSyncVector或NewSyncVectorFunc创建,
+// PushIfAbsent, Contains等按创建时指定的相等判断比较元素
+type SyncVector[T any] struct {
+	v     Vector[T]
+	equal func(a, b T) bool
+	lock  sync.RWMutex
+}
+
+func NewSyncVector[T comparable](items ...T) *SyncVector[T] {
+	return NewSyncVectorFunc(func(a, b T) bool { return a == b }, items...)
+}
+
+// 用于不可比较的元素类型, 如含有slice或map的结构体
+func NewSyncVectorFunc[T any](equal func(a, b T) bool, items ...T) *SyncVector[T] {
+	return &SyncVector[T]{v: *NewVectorOf(items...), equal: equal}
+}
+
+func (s *SyncVector[T]) Push(n T) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.v.Push(n)
+}
+
+// 不存在时才添加, 返回是否添加
+func (s *SyncVector[T]) PushIfAbsent(n T) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.v.ContainsFunc(s.is(n)) {
+		return false
+	}
+	s.v.Push(n)
+	return true
+}
+
+func (s *SyncVector[T]) Get(index int) (T, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.v.Get(index)
+}
+
+func (s *SyncVector[T]) Set(index int, n T) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.v.Set(index, n)
+}
+
+func (s *SyncVector[T]) Insert(index int, n T) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.v.Insert(index, n)
+}
+
+func (s *SyncVector[T]) Pop() (T, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.v.Pop()
+}
+
+func (s *SyncVector[T]) RemoveIndex(index int) (T, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.v.RemoveIndex(index)
+}
+
+func (s *SyncVector[T]) RemoveValue(n T) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.v.RemoveFunc(s.is(n))
+}
+
+func (s *SyncVector[T]) Clear() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.v.Clear()
+}
+
+func (s *SyncVector[T]) Contains(n T) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.v.ContainsFunc(s.is(n))
+}
+
+func (s *SyncVector[T]) IndexOf(n T) int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.v.IndexFunc(s.is(n))
+}
+
+func (s *SyncVector[T]) Length() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.v.Length()
+}
+
+// 返回当前所有元素的拷贝
+func (s *SyncVector[T]) Snapshot() []T {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return append([]T(nil), s.v.a...)
+}
+
+// 遍历调用时的快照, 遍历过程中不持有锁
+func (s *SyncVector[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, n := range s.Snapshot() {
+			if !yield(i, n) {
+				return
+			}
+		}
+	}
+}
+
+// 持有写锁执行fn, 用于需要原子完成的组合操作. fn中不能再调用s的方法
+func (s *SyncVector[T]) Update(fn func(v *Vector[T])) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fn(&s.v)
+}
+
+func (s *SyncVector[T]) is(n T) func(item T) bool {
+	return func(item T) bool { return s.equal(item, n) }
+}
@@ -0,0 +1,67 @@
+package goapl
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSyncVector(t *testing.T) {
+	vct := NewSyncVector[uint64]()
+
+	var wg sync.WaitGroup
+	var added sync.Map
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := uint64(0); j < 100; j++ {
+				if vct.PushIfAbsent(j) {
+					if _, dup := added.LoadOrStore(j, i); dup {
+						t.Error("ERR:value added twice", j)
+					}
+				}
+				vct.Contains(j)
+				vct.Snapshot()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if vct.Length() != 100 {
+		t.Fatalf("ERR:vector should have 100 ids, got %d", vct.Length())
+	}
+
+	vct.Update(func(v *Vector[uint64]) {
+		Sort(v)
+		v.Reverse()
+	})
+
+	if n, _ := vct.Get(0); n != 99 {
+		t.Error("ERR:update should sort descending")
+	}
+
+	count := 0
+	for range vct.All() {
+		vct.RemoveIndex(0)
+		count++
+	}
+	if count != 100 || vct.Length() != 0 {
+		t.Error("ERR:iteration should use snapshot")
+	}
+}
+
+func TestSyncVectorFunc(t *testing.T) {
+	type conn struct {
+		id    int
+		flags map[string]bool
+	}
+
+	vct := NewSyncVectorFunc(func(a, b conn) bool { return a.id == b.id })
+	if !vct.PushIfAbsent(conn{id: 1}) || vct.PushIfAbsent(conn{id: 1, flags: map[string]bool{}}) {
+		t.Fatal("ERR:PushIfAbsent should use the equal func")
+	}
+
+	if !vct.Contains(conn{id: 1}) || vct.IndexOf(conn{id: 2}) != -1 || vct.RemoveValue(conn{id: 1}) != 1 {
+		t.Error("ERR:lookup should use the equal func")
+	}
+}
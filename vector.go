@@ -2,59 +2,179 @@ package goapl
 
 import (
 	"errors"
-	"fmt"
+	"iter"
 )
 
-type Vector struct {
-	a   []uint64
-	num int
+var (
+	ErrIndexOutOfRange = errors.New("goapl: index out of range")
+	ErrEmpty           = errors.New("goapl: container is empty")
+)
+
+// 可存放任意类型的Vector, 按值比较的操作见Contains, IndexOf, RemoveValue等函数
+type Vector[T any] struct {
+	a []T
+}
+
+// 兼容原来只存放uint64的Vector, 保留按值比较的方法
+type Uint64Vector struct {
+	Vector[uint64]
+}
+
+func NewVector() *Uint64Vector {
+	return &Uint64Vector{Vector: *NewVectorOf[uint64]()}
+}
+
+// 删除所有等于n的元素, 返回删除的个数
+func (v *Uint64Vector) RemoveValue(n uint64) int {
+	return RemoveValue(&v.Vector, n)
+}
+
+func (v *Uint64Vector) Contains(n uint64) bool {
+	return Contains(&v.Vector, n)
+}
+
+func (v *Uint64Vector) IndexOf(n uint64) int {
+	return IndexOf(&v.Vector, n)
 }
 
-func NewVector() *Vector {
-	arr := make([]uint64, 0)
-	return &Vector{a: arr, num:0}
+func NewVectorOf[T any](items ...T) *Vector[T] {
+	arr := make([]T, 0, len(items))
+	arr = append(arr, items...)
+	return &Vector[T]{a: arr}
 }
 
-func (v *Vector) Push(n uint64) {
+func (v *Vector[T]) Push(n T) {
 	v.a = append(v.a, n)
-	v.num ++
 }
 
-func (v *Vector) Get(index int) (uint64, error) {
-	if index > len(v.a)-1 {
-		return 0, errors.New("ERR:overflow")
+func (v *Vector[T]) Get(index int) (T, error) {
+	if !v.inRange(index) {
+		var zero T
+		return zero, ErrIndexOutOfRange
 	}
 
 	return v.a[index], nil
 }
 
-func (v *Vector) RemoveIndex(index int) (uint64, error) {
-	if index > len(v.a)-1 {
-		return 0, errors.New("ERR:overflow")
+func (v *Vector[T]) Set(index int, n T) error {
+	if !v.inRange(index) {
+		return ErrIndexOutOfRange
+	}
+
+	v.a[index] = n
+	return nil
+}
+
+func (v *Vector[T]) Insert(index int, n T) error {
+	if index < 0 || index > len(v.a) {
+		return ErrIndexOutOfRange
+	}
+
+	var zero T
+	v.a = append(v.a, zero)
+	copy(v.a[index+1:], v.a[index:])
+	v.a[index] = n
+	return nil
+}
+
+func (v *Vector[T]) Pop() (T, error) {
+	if len(v.a) == 0 {
+		var zero T
+		return zero, ErrEmpty
+	}
+
+	return v.RemoveIndex(len(v.a) - 1)
+}
+
+func (v *Vector[T]) RemoveIndex(index int) (T, error) {
+	if !v.inRange(index) {
+		var zero T
+		return zero, ErrIndexOutOfRange
 	}
 
 	n := v.a[index]
-	v.a = append(v.a[:index], v.a[index+1:]...)
-	v.num --
+	copy(v.a[index:], v.a[index+1:])
+
+	// 清掉末尾的引用, 避免存放指针时内存无法回收
+	var zero T
+	v.a[len(v.a)-1] = zero
+	v.a = v.a[:len(v.a)-1]
 	return n, nil
 }
 
-func (v *Vector) RemoveValue(n uint64) {
+// 删除所有满足pred的元素, 返回删除的个数
+func (v *Vector[T]) RemoveFunc(pred func(n T) bool) int {
+	kept := v.a[:0]
+	for _, item := range v.a {
+		if !pred(item) {
+			kept = append(kept, item)
+		}
+	}
+
+	removed := len(v.a) - len(kept)
+	clear(v.a[len(kept):])
+	v.a = kept
+	return removed
+}
+
+func (v *Vector[T]) Clear() {
+	clear(v.a)
+	v.a = v.a[:0]
+}
+
+func (v *Vector[T]) ContainsFunc(pred func(n T) bool) bool {
+	return v.IndexFunc(pred) >= 0
+}
 
-	for i := 0; i < v.num; i++ {
-		if v.a[i] != n {
-			continue
+// 返回第一个满足pred的下标, 不存在时返回-1
+func (v *Vector[T]) IndexFunc(pred func(n T) bool) int {
+	for i, item := range v.a {
+		if pred(item) {
+			return i
 		}
-		v.a = append(v.a[:i], v.a[i+1:]...)
-		v.num = v.num - 1
 	}
+	return -1
+}
 
-	if len(v.a) != v.num {
-		fmt.Println("ERR")
+// 返回[from, to)区间元素的拷贝
+func (v *Vector[T]) Slice(from, to int) ([]T, error) {
+	if from < 0 || from > to || to > len(v.a) {
+		return nil, ErrIndexOutOfRange
 	}
+
+	return append([]T(nil), v.a[from:to]...), nil
 }
 
-func (v *Vector) Length() (n int) {
-	n = v.num
+// 按顺序遍历下标和元素, 用于for i, n := range v.All()
+func (v *Vector[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, n := range v.a {
+			if !yield(i, n) {
+				return
+			}
+		}
+	}
+}
+
+func (v *Vector[T]) Length() (n int) {
+	n = len(v.a)
 	return n
 }
+
+func (v *Vector[T]) inRange(index int) bool {
+	return index >= 0 && index < len(v.a)
+}
+
+// 删除v中所有等于n的元素, 返回删除的个数
+func RemoveValue[T comparable](v *Vector[T], n T) int {
+	return v.RemoveFunc(func(item T) bool { return item == n })
+}
+
+func Contains[T comparable](v *Vector[T], n T) bool {
+	return IndexOf(v, n) >= 0
+}
+
+// 返回v中第一个等于n的下标, 不存在时返回-1
+func IndexOf[T comparable](v *Vector[T], n T) int {
+	return v.IndexFunc(func(item T) bool { return item == n })
+}
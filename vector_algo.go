@@ -0,0 +1,88 @@
+package goapl
+
+import (
+	"cmp"
+	"slices"
+)
+
+// 按cmp排序, cmp返回负数表示a在b之前, 排序是稳定的
+func (v *Vector[T]) SortFunc(cmp func(a, b T) int) {
+	slices.SortStableFunc(v.a, cmp)
+}
+
+// 在按cmp排好序的Vector中查找target, 返回下标和是否找到, 未找到时下标为应插入的位置
+func (v *Vector[T]) BinarySearchFunc(target T, cmp func(a, b T) int) (int, bool) {
+	return slices.BinarySearchFunc(v.a, target, cmp)
+}
+
+// 返回满足pred的元素组成的新Vector
+func (v *Vector[T]) Filter(pred func(n T) bool) *Vector[T] {
+	out := NewVectorOf[T]()
+	for _, n := range v.a {
+		if pred(n) {
+			out.a = append(out.a, n)
+		}
+	}
+	return out
+}
+
+// 原地反转
+func (v *Vector[T]) Reverse() {
+	slices.Reverse(v.a)
+}
+
+// 原地去重, 保留每个值第一次出现的位置, 返回删除的个数
+func Unique[T comparable](v *Vector[T]) int {
+	seen := make(map[T]struct{}, len(v.a))
+	kept := v.a[:0]
+	for _, n := range v.a {
+		if _, ok := seen[n]; ok {
+			continue
+		}
+		seen[n] = struct{}{}
+		kept = append(kept, n)
+	}
+
+	removed := len(v.a) - len(kept)
+	clear(v.a[len(kept):])
+	v.a = kept
+	return removed
+}
+
+// 元素个数相同且按顺序两两满足eq时返回true
+func (v *Vector[T]) EqualFunc(other *Vector[T], eq func(a, b T) bool) bool {
+	return slices.EqualFunc(v.a, other.a, eq)
+}
+
+// 元素个数和顺序都相同时返回true
+func Equal[T comparable](v, other *Vector[T]) bool {
+	return slices.Equal(v.a, other.a)
+}
+
+// 按自然顺序排序
+func Sort[T cmp.Ordered](v *Vector[T]) {
+	slices.Sort(v.a)
+}
+
+// 在按自然顺序排好序的Vector中查找target
+func BinarySearch[T cmp.Ordered](v *Vector[T], target T) (int, bool) {
+	return slices.BinarySearch(v.a, target)
+}
+
+// 对每个元素调用fn, 返回结果组成的新Vector
+func Map[T, U any](v *Vector[T], fn func(n T) U) *Vector[U] {
+	out := &Vector[U]{a: make([]U, 0, len(v.a))}
+	for _, n := range v.a {
+		out.a = append(out.a, fn(n))
+	}
+	return out
+}
+
+// 从init开始依次把元素合并到累积值中
+func Reduce[T, A any](v *Vector[T], init A, fn func(acc A, n T) A) A {
+	acc := init
+	for _, n := range v.a {
+		acc = fn(acc, n)
+	}
+	return acc
+}
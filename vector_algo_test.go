@@ -0,0 +1,77 @@
+package goapl
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestVectorSortSearch(t *testing.T) {
+	vct := NewVectorOf(5, 3, 9, 1)
+	Sort(vct)
+
+	if !Equal(vct, NewVectorOf(1, 3, 5, 9)) {
+		t.Fatal("ERR:vector should be sorted")
+	}
+
+	if i, ok := BinarySearch(vct, 5); !ok || i != 2 {
+		t.Error("ERR:5 should be found at 2")
+	}
+
+	if i, ok := BinarySearch(vct, 4); ok || i != 2 {
+		t.Error("ERR:4 should be inserted at 2")
+	}
+
+	words := NewVectorOf("ccc", "a", "bb")
+	byLen := func(a, b string) int {
+		return len(a) - len(b)
+	}
+	words.SortFunc(byLen)
+	if !Equal(words, NewVectorOf("a", "bb", "ccc")) {
+		t.Fatal("ERR:words should be sorted by length")
+	}
+
+	if i, ok := words.BinarySearchFunc("xx", byLen); !ok || i != 1 {
+		t.Error("ERR:length 2 should be found at 1")
+	}
+}
+
+func TestVectorFunctional(t *testing.T) {
+	vct := NewVectorOf(1, 2, 3, 4, 5, 6)
+
+	even := vct.Filter(func(n int) bool {
+		return n%2 == 0
+	})
+	if !Equal(even, NewVectorOf(2, 4, 6)) || vct.Length() != 6 {
+		t.Fatal("ERR:filter should return even numbers without changing source")
+	}
+
+	strs := Map(even, strconv.Itoa)
+	joined := Reduce(strs, "", func(acc string, n string) string {
+		return acc + n
+	})
+	if joined != "246" {
+		t.Fatalf("ERR:map and reduce should give 246, got %s", joined)
+	}
+
+	vct.Reverse()
+	if v, _ := vct.Get(0); v != 6 {
+		t.Error("ERR:reverse should put 6 first")
+	}
+
+	dup := NewVectorOf("b", "a", "b", "c", "a")
+	if n := Unique(dup); n != 2 {
+		t.Errorf("ERR:unique should remove 2, got %d", n)
+	}
+	if got := strings.Join(mustSlice(t, dup), ""); got != "bac" {
+		t.Errorf("ERR:unique should keep first occurrences, got %s", got)
+	}
+}
+
+func mustSlice[T comparable](t *testing.T, v *Vector[T]) []T {
+	s, err := v.Slice(0, v.Length())
+	if err != nil {
+		t.Fatal("ERR:slice failed,", err)
+	}
+	return s
+}
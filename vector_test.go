@@ -19,4 +19,136 @@ func TestVectorAddDelete(t *testing.T) {
 		t.Fatal("ERR:index 0 should be 1")
 	}
 	
-}
\ No newline at end of file
+}
+
+func TestVectorGeneric(t *testing.T) {
+	vct := NewVectorOf("a", "b", "d")
+
+	if err := vct.Insert(2, "c"); err != nil {
+		t.Fatal("ERR:insert failed")
+	}
+
+	if err := vct.Set(0, "A"); err != nil {
+		t.Fatal("ERR:set failed")
+	}
+
+	if !Contains(vct, "c") || IndexOf(vct, "d") != 3 || IndexOf(vct, "x") != -1 {
+		t.Fatal("ERR:lookup failed")
+	}
+
+	s, err := vct.Slice(1, 3)
+	if err != nil || len(s) != 2 || s[0] != "b" || s[1] != "c" {
+		t.Fatal("ERR:slice should be [b c]")
+	}
+
+	var joined string
+	for _, item := range vct.All() {
+		joined += item
+	}
+	if joined != "Abcd" {
+		t.Fatalf("ERR:iteration should be Abcd, got %s", joined)
+	}
+
+	n, err := vct.Pop()
+	if err != nil || n != "d" || vct.Length() != 3 {
+		t.Fatal("ERR:pop should return d")
+	}
+
+	vct.Clear()
+	if vct.Length() != 0 {
+		t.Fatal("ERR:vector should be empty")
+	}
+
+	if _, err := vct.Pop(); err == nil {
+		t.Fatal("ERR:pop on empty vector should fail")
+	}
+}
+
+func TestVectorBounds(t *testing.T) {
+	vct := NewVectorOf(1, 2, 3)
+
+	if _, err := vct.Get(-1); err != ErrIndexOutOfRange {
+		t.Error("ERR:negative index should be out of range")
+	}
+
+	if _, err := vct.Get(3); err != ErrIndexOutOfRange {
+		t.Error("ERR:index 3 should be out of range")
+	}
+
+	if _, err := vct.RemoveIndex(-1); err != ErrIndexOutOfRange {
+		t.Error("ERR:negative remove should be out of range")
+	}
+
+	if err := vct.Set(-1, 0); err != ErrIndexOutOfRange {
+		t.Error("ERR:negative set should be out of range")
+	}
+
+	if err := vct.Insert(4, 0); err != ErrIndexOutOfRange {
+		t.Error("ERR:insert past end should be out of range")
+	}
+
+	if _, err := vct.Slice(-1, 2); err != ErrIndexOutOfRange {
+		t.Error("ERR:negative slice should be out of range")
+	}
+
+	if vct.Length() != 3 {
+		t.Error("ERR:failed calls should not change length")
+	}
+}
+
+func TestVectorRemoveValue(t *testing.T) {
+	vct := NewVectorOf(1, 2, 2, 3, 2, 2)
+
+	if n := RemoveValue(vct, 2); n != 4 {
+		t.Fatalf("ERR:should remove 4 values, got %d", n)
+	}
+
+	if vct.Length() != 2 || Contains(vct, 2) {
+		t.Fatal("ERR:all 2 should be removed")
+	}
+
+	if n := RemoveValue(vct, 5); n != 0 {
+		t.Error("ERR:missing value should remove nothing")
+	}
+}
+
+func TestVectorNonComparable(t *testing.T) {
+	type job struct {
+		name string
+		tags []string
+	}
+
+	vct := NewVectorOf(job{"a", nil}, job{"b", []string{"x"}}, job{"c", nil})
+	byName := func(name string) func(j job) bool {
+		return func(j job) bool { return j.name == name }
+	}
+
+	if vct.IndexFunc(byName("b")) != 1 || vct.ContainsFunc(byName("x")) {
+		t.Fatal("ERR:lookup by func failed")
+	}
+
+	if n := vct.RemoveFunc(byName("a")); n != 1 || vct.Length() != 2 {
+		t.Fatal("ERR:remove by func failed")
+	}
+
+	names := Map(vct, func(j job) string { return j.name })
+	if !Equal(names, NewVectorOf("b", "c")) {
+		t.Error("ERR:map should work on any type")
+	}
+
+	sameName := func(a, b job) bool { return a.name == b.name }
+	if !vct.EqualFunc(NewVectorOf(job{"b", nil}, job{"c", nil}), sameName) {
+		t.Error("ERR:equal by func failed")
+	}
+}
+
+func TestUint64VectorCompat(t *testing.T) {
+	vct := NewVector()
+	vct.Push(1)
+	vct.Push(2)
+	vct.Push(1)
+
+	if vct.RemoveValue(1) != 2 || !vct.Contains(2) || vct.IndexOf(2) != 0 {
+		t.Error("ERR:uint64 vector should keep value methods")
+	}
+}